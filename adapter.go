@@ -2,11 +2,13 @@ package joe
 
 import (
 	"bufio"
-	"errors"
+	"context"
 	"fmt"
 	"io"
 	"os"
 	"runtime"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/go-joe/joe/reactions"
@@ -32,21 +34,364 @@ type ReactionAwareAdapter interface {
 	React(reactions.Reaction, Message) error
 }
 
+// ReactByIDAdapter is an optional interface that Adapters can implement if
+// they support reacting to any message in a channel via its ID, including the
+// messages that were sent by the bot itself. See Bot.React(…).
+type ReactByIDAdapter interface {
+	ReactID(reaction reactions.Reaction, channel, messageID string) error
+}
+
+// EphemeralAdapter is an optional interface that Adapters can implement if
+// they support sending messages to a channel that are only visible to a single
+// user (e.g. Slack).
+type EphemeralAdapter interface {
+	SendEphemeral(channel, userID, text string) error
+}
+
+// SelfAwareAdapter is an optional interface that Adapters can implement if
+// they know the user ID under which the bot itself is connected to the chat.
+// If an Adapter implements this interface, all ReceiveMessageEvents that were
+// authored by the bot itself are ignored by the handlers that are registered
+// via Bot.Respond(…) and Bot.RespondRegex(…). This protects the bot against
+// loops if the chat echoes the messages of the bot back to the adapter. You
+// can opt out of this behavior via the WithSelfMessages() option.
+//
+// Each Adapter should document where the returned ID comes from (e.g. the
+// slack adapter uses the user ID of the bot as returned by the "auth.test"
+// API). The returned ID must match the ReceiveMessageEvent.AuthorID of the
+// messages that the bot has sent itself. If the ID is not known (yet), the
+// empty string should be returned.
+type SelfAwareAdapter interface {
+	BotUserID() string
+}
+
+// A CommandSyncer is an optional interface that Adapters can implement if the
+// chat requires that the commands of a bot are registered (e.g. as slash
+// commands in Slack or application commands in Discord). SyncCommands is
+// called by Bot.Run() with all commands of the bot (see Bot.Commands()) before
+// the Adapter is registered at the Brain. Adapters can use CommandInfo.Name()
+// to map the commands to the names that are registered at the chat. If
+// SyncCommands returns an error the bot is not started.
+type CommandSyncer interface {
+	SyncCommands([]CommandInfo) error
+}
+
+// A Drainer is an optional interface that Adapters can implement to support a
+// graceful shutdown of the bot via Bot.Drain(…). When Drain is called, the
+// Adapter must stop emitting new events (e.g. by disconnecting from the chat)
+// but it must still be able to send messages until it is closed, so the bot can
+// finish processing all pending events. Drain should return when the Adapter
+// does no longer emit any events or when the context is done.
+type Drainer interface {
+	Drain(ctx context.Context) error
+}
+
+// A ThreadReaderAdapter is an optional interface that Adapters can implement
+// if the chat supports threads and the Adapter can look up the message that
+// started a thread. The threadID corresponds to the ReceiveMessageEvent.ThreadID
+// of the messages in the thread. If the root message cannot be found, an error
+// should be returned. See Message.ThreadRoot().
+type ThreadReaderAdapter interface {
+	ThreadRoot(channel, threadID string) (Message, error)
+}
+
+// An EditingAdapter is an optional interface that Adapters can implement if the
+// chat supports editing messages that were sent by the bot. SendMessage is like
+// Adapter.Send(…) but it returns the ID of the sent message which can then be
+// passed to Edit to replace the text of that message. See
+// Message.NewProgress(…).
+type EditingAdapter interface {
+	SendMessage(text, channel string) (messageID string, err error)
+	Edit(channel, messageID, text string) error
+}
+
+// A PresenceAdapter is an optional interface that Adapters can implement if the
+// chat knows whether users are currently online. See Bot.UserPresence(…).
+type PresenceAdapter interface {
+	Presence(userID string) (Presence, error)
+}
+
+// A ChannelLister is an optional interface that Adapters can implement if they
+// can list all channels the bot is a member of. See Bot.Channels().
+type ChannelLister interface {
+	Channels() ([]Channel, error)
+}
+
+// A MemberLister is an optional interface that Adapters can implement if they
+// can list all members of a channel. See Bot.ChannelMembers(…).
+type MemberLister interface {
+	Members(channel string) ([]User, error)
+}
+
+// A Capability is an optional feature of an Adapter. Each Capability
+// corresponds to one of the optional Adapter interfaces.
+type Capability string
+
+// All Capabilities that an Adapter may support.
+const (
+	CapabilityReactions Capability = "reactions"   // see ReactionAwareAdapter
+	CapabilityEphemeral Capability = "ephemeral"   // see EphemeralAdapter
+	CapabilitySelfAware Capability = "self-aware"  // see SelfAwareAdapter
+	CapabilityCommands  Capability = "commands"    // see CommandSyncer
+	CapabilityReactByID Capability = "react-by-id" // see ReactByIDAdapter
+	CapabilityDrain     Capability = "drain"       // see Drainer
+	CapabilityThreads   Capability = "threads"     // see ThreadReaderAdapter
+	CapabilityEditing   Capability = "editing"     // see EditingAdapter
+	CapabilityPresence  Capability = "presence"    // see PresenceAdapter
+	CapabilityChannels  Capability = "channels"    // see ChannelLister
+	CapabilityMembers   Capability = "members"     // see MemberLister
+)
+
+// capabilities maps each Capability to a function that checks if an Adapter
+// implements the corresponding interface.
+var capabilities = map[Capability]func(Adapter) bool{
+	CapabilityReactions: func(a Adapter) bool { _, ok := a.(ReactionAwareAdapter); return ok },
+	CapabilityEphemeral: func(a Adapter) bool { _, ok := a.(EphemeralAdapter); return ok },
+	CapabilitySelfAware: func(a Adapter) bool { _, ok := a.(SelfAwareAdapter); return ok },
+	CapabilityCommands:  func(a Adapter) bool { _, ok := a.(CommandSyncer); return ok },
+	CapabilityReactByID: func(a Adapter) bool { _, ok := a.(ReactByIDAdapter); return ok },
+	CapabilityDrain:     func(a Adapter) bool { _, ok := a.(Drainer); return ok },
+	CapabilityThreads:   func(a Adapter) bool { _, ok := a.(ThreadReaderAdapter); return ok },
+	CapabilityEditing:   func(a Adapter) bool { _, ok := a.(EditingAdapter); return ok },
+	CapabilityPresence:  func(a Adapter) bool { _, ok := a.(PresenceAdapter); return ok },
+	CapabilityChannels:  func(a Adapter) bool { _, ok := a.(ChannelLister); return ok },
+	CapabilityMembers:   func(a Adapter) bool { _, ok := a.(MemberLister); return ok },
+}
+
+// AdapterSupports returns true if the Adapter implements the optional
+// interface of the given Capability. Adapters that were wrapped by an option
+// such as WithSendRetry(…) are checked by their original implementation.
+func AdapterSupports(a Adapter, c Capability) bool {
+	a = UnwrapAdapter(a)
+	check, ok := capabilities[c]
+	return ok && a != nil && check(a)
+}
+
+// UnwrapAdapter returns the original Adapter if the given Adapter was wrapped by
+// an option such as WithSendRetry(…), WithObserver(…) or WithLoopGuard(…).
+// Wrappers implement all optional Adapter interfaces, so a type assertion on a
+// wrapped Adapter cannot tell which features are actually supported. Modules
+// should use AdapterSupports(…) to check for an optional interface and
+// UnwrapAdapter(…) to access adapter specific methods. Adapters that are not
+// wrapped are returned as they are.
+func UnwrapAdapter(a Adapter) Adapter {
+	for {
+		w, ok := a.(interface{ Unwrap() Adapter })
+		if !ok {
+			return a
+		}
+		a = w.Unwrap()
+	}
+}
+
+// AdapterCapabilities returns all Capabilities that the Adapter supports in
+// alphabetical order.
+func AdapterCapabilities(a Adapter) []Capability {
+	var supported []Capability
+	for c := range capabilities {
+		if AdapterSupports(a, c) {
+			supported = append(supported, c)
+		}
+	}
+
+	sort.Slice(supported, func(i, j int) bool {
+		return supported[i] < supported[j]
+	})
+
+	return supported
+}
+
+// wrappedAdapter wraps an Adapter to intercept all messages that are sent (e.g.
+// to retry them). It also implements all optional Adapter interfaces by
+// delegating to the wrapped Adapter so wrapping does not hide any features.
+// Methods of interfaces that the wrapped Adapter does not implement behave as
+// if the feature was not supported, therefore all code that checks for an
+// optional interface must also use AdapterSupports(…).
+type wrappedAdapter struct {
+	Adapter
+	wrap func(channel, text string, send func() error) error
+}
+
+// Unwrap returns the wrapped Adapter so AdapterSupports(…) can check which
+// optional interfaces are actually implemented.
+func (a *wrappedAdapter) Unwrap() Adapter {
+	return a.Adapter
+}
+
+func (a *wrappedAdapter) Send(text, channel string) error {
+	return a.wrap(channel, text, func() error {
+		return a.Adapter.Send(text, channel)
+	})
+}
+
+func (a *wrappedAdapter) SendEphemeral(channel, userID, text string) error {
+	adapter, ok := a.Adapter.(EphemeralAdapter)
+	if !ok {
+		return a.Send(text, channel)
+	}
+
+	return a.wrap(channel, text, func() error {
+		return adapter.SendEphemeral(channel, userID, text)
+	})
+}
+
+func (a *wrappedAdapter) React(r reactions.Reaction, msg Message) error {
+	adapter, ok := a.Adapter.(ReactionAwareAdapter)
+	if !ok {
+		return ErrNotImplemented
+	}
+
+	return adapter.React(r, msg)
+}
+
+func (a *wrappedAdapter) ReactID(r reactions.Reaction, channel, messageID string) error {
+	adapter, ok := a.Adapter.(ReactByIDAdapter)
+	if !ok {
+		return ErrNotImplemented
+	}
+
+	return adapter.ReactID(r, channel, messageID)
+}
+
+func (a *wrappedAdapter) ThreadRoot(channel, threadID string) (Message, error) {
+	adapter, ok := a.Adapter.(ThreadReaderAdapter)
+	if !ok {
+		return Message{}, ErrNotImplemented
+	}
+
+	return adapter.ThreadRoot(channel, threadID)
+}
+
+func (a *wrappedAdapter) SendMessage(text, channel string) (string, error) {
+	adapter, ok := a.Adapter.(EditingAdapter)
+	if !ok {
+		return "", ErrNotImplemented
+	}
+
+	var id string
+	err := a.wrap(channel, text, func() (err error) {
+		id, err = adapter.SendMessage(text, channel)
+		return err
+	})
+
+	return id, err
+}
+
+func (a *wrappedAdapter) Edit(channel, messageID, text string) error {
+	adapter, ok := a.Adapter.(EditingAdapter)
+	if !ok {
+		return ErrNotImplemented
+	}
+
+	return a.wrap(channel, text, func() error {
+		return adapter.Edit(channel, messageID, text)
+	})
+}
+
+func (a *wrappedAdapter) Presence(userID string) (Presence, error) {
+	adapter, ok := a.Adapter.(PresenceAdapter)
+	if !ok {
+		return "", ErrNotImplemented
+	}
+
+	return adapter.Presence(userID)
+}
+
+func (a *wrappedAdapter) Channels() ([]Channel, error) {
+	adapter, ok := a.Adapter.(ChannelLister)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+
+	return adapter.Channels()
+}
+
+func (a *wrappedAdapter) Members(channel string) ([]User, error) {
+	adapter, ok := a.Adapter.(MemberLister)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+
+	return adapter.Members(channel)
+}
+
+func (a *wrappedAdapter) Drain(ctx context.Context) error {
+	adapter, ok := a.Adapter.(Drainer)
+	if !ok {
+		return nil
+	}
+
+	return adapter.Drain(ctx)
+}
+
+func (a *wrappedAdapter) BotUserID() string {
+	adapter, ok := a.Adapter.(SelfAwareAdapter)
+	if !ok {
+		return ""
+	}
+
+	return adapter.BotUserID()
+}
+
+func (a *wrappedAdapter) SyncCommands(commands []CommandInfo) error {
+	adapter, ok := a.Adapter.(CommandSyncer)
+	if !ok {
+		return nil
+	}
+
+	return adapter.SyncCommands(commands)
+}
+
 // The CLIAdapter is the default Adapter implementation that the bot uses if no
 // other adapter was configured. It emits a ReceiveMessageEvent for each line it
 // receives from stdin and prints all sent messages to stdout.
 //
-// The CLIAdapter does not set the Message.Data field.
+// The CLIAdapter does not set the Message.Data field. It also does not implement
+// the SelfAwareAdapter interface because it never receives its own messages.
 type CLIAdapter struct {
-	Prefix  string
-	Input   io.ReadCloser
-	Output  io.Writer
-	Logger  *zap.Logger
-	Author  string     // used to set the author of the messages, defaults to os.Getenv("USER)
-	mu      sync.Mutex // protects the Output and closing channel
-	closing chan chan error
+	Prefix     string
+	PrefixFunc func() string // optional, renders the prefix each time it is printed instead of using Prefix
+	Input      io.ReadCloser
+	Output     io.Writer
+	Logger     *zap.Logger
+	Author     string     // used to set the author of the messages, defaults to os.Getenv("USER)
+	mu         sync.Mutex // protects the Output, closing channel and started flag
+	closing    chan chan error
+	started    bool // true if the loop was started via RegisterAt(…)
+
+	// Channel is set as ReceiveMessageEvent.Channel on all emitted events which
+	// allows to test channel specific handlers locally. Defaults to "".
+	Channel string
+
+	// MultiLineTerminator enables multi-line messages if it is not empty. In
+	// this case all lines are collected until a line is read which is equal to
+	// the terminator (e.g. "."). Then all collected lines are emitted as a
+	// single message. If the input ends before the terminator, the collected
+	// lines are emitted as well.
+	MultiLineTerminator string
+
+	// Colors controls whether the prefix and the messages of the bot are
+	// colorized via ANSI escape codes. Defaults to ColorsNever.
+	Colors ColorMode
 }
 
+// ColorMode controls if the CLIAdapter writes colorized output.
+type ColorMode int
+
+// All available ColorModes of the CLIAdapter.
+const (
+	ColorsNever  ColorMode = iota // never colorize the output
+	ColorsAuto                    // colorize the output only if it is written to a terminal
+	ColorsAlways                  // always colorize the output
+)
+
+// ANSI escape codes used by the CLIAdapter to colorize its output.
+const (
+	colorReset  = "\x1b[0m"
+	colorPrefix = "\x1b[1;32m" // bold green
+	colorBot    = "\x1b[36m"   // cyan
+)
+
 // NewCLIAdapter creates a new CLIAdapter. The caller must call Close
 // to make the CLIAdapter stop reading messages and emitting events.
 func NewCLIAdapter(name string, logger *zap.Logger) *CLIAdapter {
@@ -66,9 +411,13 @@ func NewCLIAdapter(name string, logger *zap.Logger) *CLIAdapter {
 // ready to accept input.
 func (a *CLIAdapter) RegisterAt(brain *Brain) {
 	brain.RegisterHandler(func(evt InitEvent) {
-		_ = a.print(a.Prefix)
+		_ = a.printPrefix()
 	})
 
+	a.mu.Lock()
+	a.started = true
+	a.mu.Unlock()
+
 	go a.loop(brain)
 }
 
@@ -88,7 +437,8 @@ func (a *CLIAdapter) loop(brain *Brain) {
 		callback <- evt
 	}
 
-	var lines = input // channel represents the case that we receive a new message
+	var lines = input    // channel represents the case that we receive a new message
+	var pending []string // collected lines of a multi-line message
 
 	for {
 		select {
@@ -96,22 +446,39 @@ func (a *CLIAdapter) loop(brain *Brain) {
 			if !ok {
 				// no more input from stdin
 				lines = nil // disable this case and wait for closing signal
-				continue
+				if len(pending) == 0 {
+					continue
+				}
+
+				// The input ended before the terminator of a multi-line
+				// message but we do not want to lose what was typed.
+				a.Logger.Debug("Input ended before multi-line terminator, emitting pending lines",
+					zap.Int("lines", len(pending)),
+				)
+				msg, pending = strings.Join(pending, "\n"), nil
+			} else if a.MultiLineTerminator != "" {
+				if msg != a.MultiLineTerminator {
+					pending = append(pending, msg)
+					continue
+				}
+
+				msg = strings.Join(pending, "\n")
+				pending = nil
 			}
 
 			lines = nil // disable this case and wait for the callback
-			brain.Emit(ReceiveMessageEvent{Text: msg, AuthorID: a.Author}, callbackFun)
+			brain.Emit(ReceiveMessageEvent{Text: msg, AuthorID: a.Author, Channel: a.Channel}, callbackFun)
 
 		case <-callback:
 			// This case is executed after all ReceiveMessageEvent handlers have
 			// completed and we can continue with the next line.
-			_ = a.print(a.Prefix)
+			_ = a.printPrefix()
 			lines = input // activate first case again
 
 		case result := <-a.closing:
 			if lines == nil {
 				// We were just waiting for our callback
-				_ = a.print(a.Prefix)
+				_ = a.printPrefix()
 			}
 
 			_ = a.print("\n")
@@ -121,6 +488,51 @@ func (a *CLIAdapter) loop(brain *Brain) {
 	}
 }
 
+// prefix returns the prefix that is printed whenever the CLIAdapter is ready
+// to accept new input.
+func (a *CLIAdapter) prefix() string {
+	if a.PrefixFunc != nil {
+		return a.PrefixFunc()
+	}
+
+	return a.Prefix
+}
+
+func (a *CLIAdapter) printPrefix() error {
+	return a.print(a.colorize(colorPrefix, a.prefix()))
+}
+
+// colorize wraps the text in the given ANSI color if the CLIAdapter should
+// write colorized output.
+func (a *CLIAdapter) colorize(color, text string) string {
+	switch a.Colors {
+	case ColorsAlways:
+	case ColorsAuto:
+		if !isTerminal(a.Output) {
+			return text
+		}
+	default:
+		return text
+	}
+
+	return color + text + colorReset
+}
+
+// isTerminal returns true if w is a file which refers to a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
 // ReadLines reads lines from stdin and returns them in a channel.
 // All strings in the returned channel will not include the trailing newline.
 // The channel is closed automatically when a.Input is closed.
@@ -159,23 +571,36 @@ func (a *CLIAdapter) readLines() <-chan string {
 // Send implements the Adapter interface by sending the given text to stdout.
 // The channel argument is required by the Adapter interface but is otherwise ignored.
 func (a *CLIAdapter) Send(text, channel string) error {
-	return a.print(text + "\n")
+	return a.print(a.colorize(colorBot, text) + "\n")
 }
 
 // React implements the optional ReactionAwareAdapter interface by simply
 // printing the given reaction as UTF8 emoji to the CLI.
 func (a *CLIAdapter) React(r reactions.Reaction, _ Message) error {
-	return a.print(r.String() + "\n")
+	return a.print(a.colorize(colorBot, r.String()) + "\n")
 }
 
 // Close makes the CLIAdapter stop emitting any new events or printing any output.
-// Calling this function more than once will result in an error.
+// Calling this function more than once will return ErrAdapterClosed.
 func (a *CLIAdapter) Close() error {
 	if a.closing == nil {
-		return errors.New("already closed")
+		return ErrAdapterClosed
 	}
 
 	a.Logger.Debug("Closing CLIAdapter")
+
+	a.mu.Lock()
+	started := a.started
+	if !started {
+		// There is no loop that could close the input for us.
+		a.closing = nil
+	}
+	a.mu.Unlock()
+
+	if !started {
+		return a.Input.Close()
+	}
+
 	callback := make(chan error)
 	a.closing <- callback
 	err := <-callback
@@ -191,11 +616,12 @@ func (a *CLIAdapter) Close() error {
 
 func (a *CLIAdapter) print(msg string) error {
 	a.mu.Lock()
+	defer a.mu.Unlock()
+
 	if a.closing == nil {
-		return errors.New("adapter is closed")
+		return ErrAdapterClosed
 	}
-	_, err := fmt.Fprint(a.Output, msg)
-	a.mu.Unlock()
 
+	_, err := fmt.Fprint(a.Output, msg)
 	return err
 }
@@ -2,7 +2,9 @@ package joe_test
 
 import (
 	"bytes"
+	"fmt"
 	"io/ioutil"
+	"sync/atomic"
 	"testing"
 
 	"github.com/go-joe/joe"
@@ -49,6 +51,93 @@ func TestCLIAdapter_Register(t *testing.T) {
 	assert.Contains(t, output.String(), "test > ")
 }
 
+func TestCLIAdapter_PrefixFunc(t *testing.T) {
+	input := new(bytes.Buffer)
+	a, output := cliTestAdapter(t)
+	a.Input = ioutil.NopCloser(input)
+	brain := joetest.NewBrain(t)
+	messages := brain.Events()
+
+	var n int32
+	a.PrefixFunc = func() string {
+		return fmt.Sprintf("test %d > ", atomic.AddInt32(&n, 1))
+	}
+
+	input.WriteString("Hello\n")
+	input.WriteString("World\n")
+	a.RegisterAt(brain.Brain)
+
+	<-messages
+	<-messages
+
+	brain.Finish()
+	assert.NoError(t, a.Close())
+	assert.Contains(t, output.String(), "test 1 > ")
+	assert.Contains(t, output.String(), "test 2 > ")
+}
+
+func TestCLIAdapter_Channel(t *testing.T) {
+	input := new(bytes.Buffer)
+	a, _ := cliTestAdapter(t)
+	a.Input = ioutil.NopCloser(input)
+	a.Channel = "#general"
+	brain := joetest.NewBrain(t)
+	messages := brain.Events()
+
+	input.WriteString("Hello\n")
+	a.RegisterAt(brain.Brain)
+
+	msg := <-messages
+	assert.Equal(t, "#general", msg.Data.(joe.ReceiveMessageEvent).Channel)
+
+	brain.Finish()
+	assert.NoError(t, a.Close())
+}
+
+func TestCLIAdapter_MultiLine(t *testing.T) {
+	input := new(bytes.Buffer)
+	a, _ := cliTestAdapter(t)
+	a.Input = ioutil.NopCloser(input)
+	a.MultiLineTerminator = "."
+	brain := joetest.NewBrain(t)
+	messages := brain.Events()
+
+	input.WriteString("Hello\n")
+	input.WriteString("World\n")
+	input.WriteString(".\n")
+	input.WriteString("Single line\n")
+	input.WriteString(".\n")
+	a.RegisterAt(brain.Brain)
+
+	msg1 := <-messages
+	msg2 := <-messages
+
+	assert.Equal(t, "Hello\nWorld", msg1.Data.(joe.ReceiveMessageEvent).Text)
+	assert.Equal(t, "Single line", msg2.Data.(joe.ReceiveMessageEvent).Text)
+
+	brain.Finish()
+	assert.NoError(t, a.Close())
+}
+
+func TestCLIAdapter_MultiLine_EOF(t *testing.T) {
+	input := new(bytes.Buffer)
+	a, _ := cliTestAdapter(t)
+	a.Input = ioutil.NopCloser(input)
+	a.MultiLineTerminator = "."
+	brain := joetest.NewBrain(t)
+	messages := brain.Events()
+
+	input.WriteString("Hello\n")
+	input.WriteString("World\n")
+	a.RegisterAt(brain.Brain)
+
+	msg := <-messages
+	assert.Equal(t, "Hello\nWorld", msg.Data.(joe.ReceiveMessageEvent).Text)
+
+	brain.Finish()
+	assert.NoError(t, a.Close())
+}
+
 func TestCLIAdapter_Send(t *testing.T) {
 	a, output := cliTestAdapter(t)
 	err := a.Send("Hello World", "")
@@ -63,6 +152,39 @@ func TestCLIAdapter_React(t *testing.T) {
 	assert.Equal(t, "👍\n", output.String())
 }
 
+func TestCLIAdapter_Colors(t *testing.T) {
+	cases := map[joe.ColorMode]string{
+		joe.ColorsNever:  "Hello World\n",
+		joe.ColorsAuto:   "Hello World\n", // a bytes.Buffer is not a terminal
+		joe.ColorsAlways: "\x1b[36mHello World\x1b[0m\n",
+	}
+
+	for mode, expected := range cases {
+		a, output := cliTestAdapter(t)
+		a.Colors = mode
+		err := a.Send("Hello World", "")
+		require.NoError(t, err)
+		assert.Equal(t, expected, output.String(), "color mode %d", mode)
+	}
+}
+
+func TestCLIAdapter_Colors_Prefix(t *testing.T) {
+	input := new(bytes.Buffer)
+	a, output := cliTestAdapter(t)
+	a.Input = ioutil.NopCloser(input)
+	a.Colors = joe.ColorsAlways
+	brain := joetest.NewBrain(t)
+	messages := brain.Events()
+
+	input.WriteString("Hello\n")
+	a.RegisterAt(brain.Brain)
+	<-messages
+
+	brain.Finish()
+	assert.NoError(t, a.Close())
+	assert.Contains(t, output.String(), "\x1b[1;32mtest > \x1b[0m")
+}
+
 func TestCLIAdapter_Send_Author(t *testing.T) {
 	input := new(bytes.Buffer)
 	a, _ := cliTestAdapter(t)
@@ -95,5 +217,21 @@ func TestCLIAdapter_Close(t *testing.T) {
 	assert.Equal(t, "\n", output.String())
 
 	err = a.Close()
-	assert.EqualError(t, err, "already closed")
+	assert.Equal(t, joe.ErrAdapterClosed, err)
+
+	err = a.Send("foo", "")
+	assert.Equal(t, joe.ErrAdapterClosed, err)
+}
+
+func TestCLIAdapter_Close_NotRegistered(t *testing.T) {
+	a, output := cliTestAdapter(t)
+	a.Input = ioutil.NopCloser(new(bytes.Buffer))
+
+	// Closing must not block if the adapter was never started.
+	err := a.Close()
+	require.NoError(t, err)
+	assert.Empty(t, output.String())
+
+	err = a.Close()
+	assert.Equal(t, joe.ErrAdapterClosed, err)
 }
@@ -0,0 +1,23 @@
+package joe
+
+// WithAdapterEvents is an option to let the Adapter emit an AdapterEvent for
+// each event of the chat that it does not handle otherwise. This is disabled by
+// default because some chats send a lot of events that most bots do not need.
+func WithAdapterEvents() Module {
+	return ModuleFunc(func(conf *Config) error {
+		conf.brain.adapterEvents = true
+		return nil
+	})
+}
+
+// EmitAdapterEvent is meant to be called by Adapters for each event of the chat
+// that they do not map to any other event type. The AdapterEvent is only
+// emitted if the bot was configured with the WithAdapterEvents() option, so
+// Adapters do not need to check this themselves.
+func (b *Brain) EmitAdapterEvent(typ string, raw interface{}) {
+	if !b.adapterEvents {
+		return
+	}
+
+	b.Emit(AdapterEvent{Type: typ, Raw: raw})
+}
@@ -0,0 +1,41 @@
+package joe_test
+
+import (
+	"testing"
+
+	"github.com/go-joe/joe"
+	"github.com/go-joe/joe/joetest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithAdapterEvents(t *testing.T) {
+	type topicChange struct{ Topic string }
+
+	cases := map[string]struct {
+		modules  []joe.Module
+		expected []joe.AdapterEvent
+	}{
+		"enabled": {
+			modules:  []joe.Module{joe.WithAdapterEvents()},
+			expected: []joe.AdapterEvent{{Type: "channel_topic", Raw: topicChange{Topic: "deployments"}}},
+		},
+		"disabled": {},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			b := joetest.NewBot(t, c.modules...)
+
+			var events []joe.AdapterEvent
+			b.Brain.RegisterHandler(func(evt joe.AdapterEvent) {
+				events = append(events, evt)
+			})
+
+			b.Start()
+			b.Brain.EmitAdapterEvent("channel_topic", topicChange{Topic: "deployments"})
+			b.Stop()
+
+			assert.Equal(t, c.expected, events)
+		})
+	}
+}
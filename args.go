@@ -0,0 +1,90 @@
+package joe
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Args splits the text of the message into arguments like a shell does (see
+// SplitArgs(…)). This makes it easy to parse commands that accept arguments
+// with spaces, such as `remember "foo bar" is "baz qux"`, without crafting a
+// complex regular expression. Note that the first argument is usually the name
+// of the command itself.
+func (msg *Message) Args() []string {
+	return SplitArgs(msg.Text)
+}
+
+// SplitArgs splits the given text at all whitespace that is not quoted or
+// escaped. It can be used to split the sub matches of a message (see
+// Message.Matches) into arguments. The following rules apply:
+//
+//	"foo bar"  double quotes group an argument, \" and \\ are escaped within
+//	'foo bar'  single quotes group an argument without any escapes
+//	foo\ bar   a backslash escapes the next character outside of quotes
+//
+// Since single quotes are often used as apostrophes in chat messages, they only
+// start a quoted argument at the beginning of an argument (e.g. don't is a
+// single argument). Typographic ("smart") quotes are treated like their ASCII
+// counterparts. If a quote is not terminated, the argument ends at the end of
+// the text. Empty quotes result in an empty argument.
+func SplitArgs(text string) []string {
+	var (
+		args    []string
+		current strings.Builder
+		inArg   bool // true if we are within an argument, even if it is still empty
+		quote   rune // the quote that started the current quoted section, if any
+		escaped bool
+	)
+
+	for _, r := range smartQuotes.Replace(text) {
+		switch {
+		case escaped:
+			if quote == '"' && r != '"' && r != '\\' {
+				// Within double quotes, a backslash only escapes quotes and
+				// backslashes.
+				current.WriteRune('\\')
+			}
+			current.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case quote == '"':
+			switch r {
+			case '"':
+				quote = 0
+			case '\\':
+				escaped = true
+			default:
+				current.WriteRune(r)
+			}
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		case r == '\\':
+			escaped, inArg = true, true
+		case r == '"', r == '\'' && !inArg:
+			quote, inArg = r, true
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if escaped {
+		// A trailing backslash has nothing to escape so we keep it.
+		current.WriteRune('\\')
+	}
+
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	return args
+}
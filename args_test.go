@@ -0,0 +1,39 @@
+package joe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitArgs(t *testing.T) {
+	cases := map[string][]string{
+		``:                                    nil,
+		`   `:                                 nil,
+		`deploy`:                              {"deploy"},
+		`  deploy   api  `:                    {"deploy", "api"},
+		`remember "foo bar" is "baz qux"`:     {"remember", "foo bar", "is", "baz qux"},
+		`say 'hello "world"'`:                 {"say", `hello "world"`},
+		`say "hello 'world'"`:                 {"say", "hello 'world'"},
+		`say "a \"quoted\" \\ word"`:          {"say", `a "quoted" \ word`},
+		`say "C:\Users"`:                      {"say", `C:\Users`},
+		`say 'no \' escapes`:                  {"say", `no \`, "escapes"},
+		`foo\ bar baz`:                        {"foo bar", "baz"},
+		`key="foo bar"`:                       {"key=foo bar"},
+		`don't stop`:                          {"don't", "stop"},
+		`set "" empty`:                        {"set", "", "empty"},
+		`say "unterminated quote`:             {"say", "unterminated quote"},
+		`trailing\`:                           {`trailing\`},
+		"multi\nline\targs":                   {"multi", "line", "args"},
+		"\u201Csmart quotes\u201D don\u2019t": {"smart quotes", "don't"},
+	}
+
+	for input, expected := range cases {
+		assert.Equal(t, expected, SplitArgs(input), "%q", input)
+	}
+}
+
+func TestMessage_Args(t *testing.T) {
+	msg := Message{Text: `remember "foo bar" is "baz qux"`}
+	assert.Equal(t, []string{"remember", "foo bar", "is", "baz qux"}, msg.Args())
+}
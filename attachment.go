@@ -0,0 +1,57 @@
+package joe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// An Attachment is a file that was attached to a received message (e.g. an
+// image or a log file). Attachments are only populated by Adapters that support
+// them (see ReceiveMessageEvent.Attachments).
+//
+// Handlers can download the content of an Attachment via Attachment.Open(…).
+// Adapters either set the Fetch function (e.g. if downloading the file requires
+// authentication) or only the URL if the file can be downloaded directly.
+type Attachment struct {
+	Name        string // the file name, if known
+	ContentType string // the MIME type (e.g. "text/plain"), if known
+	Size        int64  // the size in bytes, zero if unknown
+	URL         string // the URL of the file, if any
+
+	// Fetch returns the content of the file. If it is nil, the content is
+	// downloaded from the URL instead.
+	Fetch func(ctx context.Context) (io.ReadCloser, error)
+}
+
+// Open returns the content of the attachment. It uses the Fetch function if
+// the Adapter has set it or otherwise downloads the URL via HTTP. The caller
+// must close the returned reader.
+func (a Attachment) Open(ctx context.Context) (io.ReadCloser, error) {
+	if a.Fetch != nil {
+		return a.Fetch(ctx)
+	}
+
+	if a.URL == "" {
+		return nil, errors.New("attachment has neither a fetch function nor a URL")
+	}
+
+	req, err := http.NewRequest(http.MethodGet, a.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download attachment: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to download attachment: unexpected status %s", resp.Status)
+	}
+
+	return resp.Body, nil
+}
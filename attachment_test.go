@@ -0,0 +1,75 @@
+package joe_test
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-joe/joe"
+	"github.com/go-joe/joe/joetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachment_Open(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/error.log" {
+			http.NotFound(w, r)
+			return
+		}
+
+		_, _ = w.Write([]byte("panic: oops"))
+	}))
+	defer srv.Close()
+
+	read := func(a joe.Attachment) (string, error) {
+		r, err := a.Open(context.Background())
+		if err != nil {
+			return "", err
+		}
+
+		defer r.Close()
+		data, err := ioutil.ReadAll(r)
+		return string(data), err
+	}
+
+	content, err := read(joe.Attachment{URL: srv.URL + "/error.log"})
+	require.NoError(t, err)
+	assert.Equal(t, "panic: oops", content)
+
+	content, err = read(joe.Attachment{
+		URL: srv.URL + "/error.log",
+		Fetch: func(context.Context) (io.ReadCloser, error) {
+			return ioutil.NopCloser(strings.NewReader("fetched")), nil
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "fetched", content, "the fetch function should be preferred over the URL")
+
+	_, err = read(joe.Attachment{URL: srv.URL + "/missing.log"})
+	assert.EqualError(t, err, "failed to download attachment: unexpected status 404 Not Found")
+
+	_, err = read(joe.Attachment{Name: "error.log"})
+	assert.EqualError(t, err, "attachment has neither a fetch function nor a URL")
+}
+
+func TestBot_Respond_Attachments(t *testing.T) {
+	b := joetest.NewBot(t)
+
+	var attachments []joe.Attachment
+	b.Respond("analyze this", func(msg joe.Message) error {
+		attachments = msg.Attachments
+		return nil
+	})
+
+	b.Start()
+	defer b.Stop()
+
+	expected := []joe.Attachment{{Name: "error.log", ContentType: "text/plain", URL: "https://example.com/error.log"}}
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "analyze this", Attachments: expected})
+	assert.Equal(t, expected, attachments)
+}
@@ -0,0 +1,118 @@
+package joe
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// auditKeyPrefix is the key prefix in the Storage under which all entries of
+// the audit log are stored.
+const auditKeyPrefix = "joe.audit."
+
+// An AuditEntry records a single attempt to use a command that was registered
+// via Bot.RespondAuthorized(…). See WithAuditLog(…).
+type AuditEntry struct {
+	Time    time.Time `json:"time"`
+	UserID  string    `json:"user_id"`
+	Command string    `json:"command"` // the name of the command, see CommandInfo.Name()
+	Scope   string    `json:"scope"`   // the scope that is required to use the command
+	Channel string    `json:"channel"`
+	Allowed bool      `json:"allowed"` // false if the user did not have the required scope
+}
+
+// WithAuditLog is an option to record each attempt to use a command that was
+// registered via Bot.RespondAuthorized(…) in the Storage of the bot. The
+// entries can be read via Bot.AuditLog(…). Since the audit log is never
+// truncated by the bot itself, you should use a persistent Memory and remove
+// old entries yourself if required.
+func WithAuditLog() Module {
+	return ModuleFunc(func(conf *Config) error {
+		conf.auditLog = true
+		return nil
+	})
+}
+
+// RespondAuthorized is like Bot.Respond(…) but the handler is only executed if
+// the author of the message has been granted the given scope (see
+// Auth.CheckPermission(…)). Otherwise the bot tells the user that they are not
+// allowed to use the command. If the WithAuditLog(…) option is used, each
+// attempt to use the command is recorded in the audit log.
+func (b *Bot) RespondAuthorized(scope, msg string, fun func(Message) error) {
+	command := CommandInfo{Pattern: "^" + msg + "$"}.Name()
+	b.Respond(msg, func(msg Message) error {
+		err := b.Auth.CheckPermission(scope, msg.AuthorID)
+		if err != nil && !errors.Is(err, ErrNotAllowed) {
+			return err
+		}
+
+		allowed := err == nil
+		if b.auditLog {
+			b.audit(AuditEntry{
+				UserID:  msg.AuthorID,
+				Command: command,
+				Scope:   scope,
+				Channel: msg.Channel,
+				Allowed: allowed,
+			})
+		}
+
+		if !allowed {
+			return msg.RespondE("Sorry, you are not allowed to do that")
+		}
+
+		return fun(msg)
+	})
+}
+
+// audit stores the entry in the audit log. Errors are only logged so a Memory
+// outage does not prevent users from using their commands.
+func (b *Bot) audit(entry AuditEntry) {
+	entry.Time = b.clock.Now()
+
+	// The keys sort chronologically and the sequence number makes them unique
+	// even if multiple entries are written at the same time.
+	seq := atomic.AddUint32(&b.auditSeq, 1)
+	key := fmt.Sprintf("%s%s.%010d", auditKeyPrefix, entry.Time.UTC().Format("20060102T150405.000000000"), seq)
+
+	err := b.Store.Set(key, entry)
+	if err != nil {
+		b.Logger.Error("Failed to write audit log",
+			zap.String("command", entry.Command),
+			zap.String("user_id", entry.UserID),
+			zap.Error(err),
+		)
+	}
+}
+
+// AuditLog returns all entries of the audit log that have been recorded at or
+// after the given time, ordered by time. See WithAuditLog(…).
+func (b *Bot) AuditLog(since time.Time) ([]AuditEntry, error) {
+	keys, err := b.Store.Keys()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit log: %w", err)
+	}
+
+	var entries []AuditEntry
+	for _, key := range keys {
+		if !strings.HasPrefix(key, auditKeyPrefix) {
+			continue
+		}
+
+		var entry AuditEntry
+		ok, err := b.Store.Get(key, &entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read audit log entry %q: %w", key, err)
+		}
+
+		if ok && !entry.Time.Before(since) {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,79 @@
+package joe_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-joe/joe"
+	"github.com/go-joe/joe/joetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBot_RespondAuthorized(t *testing.T) {
+	b := joetest.NewBot(t)
+
+	var calls int
+	b.RespondAuthorized("deploy", "deploy (.+)", func(msg joe.Message) error {
+		calls++
+		return msg.RespondE("deploying %s", msg.Matches[0])
+	})
+
+	_, err := b.Auth.Grant("deploy", "alice")
+	require.NoError(t, err)
+
+	b.Start()
+	defer b.Stop()
+
+	send := func(author string) string {
+		b.ReadOutput() // discard any previous output
+		b.EmitSync(joe.ReceiveMessageEvent{Text: "deploy prod", AuthorID: author})
+		return strings.TrimSuffix(b.ReadOutput(), "\n")
+	}
+
+	assert.Equal(t, "deploying prod", send("alice"))
+	assert.Equal(t, "Sorry, you are not allowed to do that", send("bob"))
+	assert.Equal(t, 1, calls)
+
+	entries, err := b.AuditLog(time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, entries, "audit log should be disabled by default")
+}
+
+func TestWithAuditLog(t *testing.T) {
+	start := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := joetest.NewClock(start)
+	b := joetest.NewBot(t, joe.WithAuditLog(), joe.WithClock(clock))
+
+	b.RespondAuthorized("deploy", "deploy (.+)", func(msg joe.Message) error {
+		return nil
+	})
+	b.Respond("ping", func(msg joe.Message) error {
+		return nil
+	})
+
+	_, err := b.Auth.Grant("deploy", "alice")
+	require.NoError(t, err)
+
+	b.Start()
+	defer b.Stop()
+
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "deploy prod", AuthorID: "alice", Channel: "ops"})
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "ping", AuthorID: "alice", Channel: "ops"})
+	clock.Advance(time.Hour)
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "deploy prod", AuthorID: "bob", Channel: "general"})
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "deploy staging", AuthorID: "alice", Channel: "general"})
+
+	entries, err := b.AuditLog(start)
+	require.NoError(t, err)
+	assert.Equal(t, []joe.AuditEntry{
+		{Time: start, UserID: "alice", Command: "deploy", Scope: "deploy", Channel: "ops", Allowed: true},
+		{Time: start.Add(time.Hour), UserID: "bob", Command: "deploy", Scope: "deploy", Channel: "general", Allowed: false},
+		{Time: start.Add(time.Hour), UserID: "alice", Command: "deploy", Scope: "deploy", Channel: "general", Allowed: true},
+	}, entries)
+
+	entries, err = b.AuditLog(start.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
@@ -1,7 +1,6 @@
 package joe
 
 import (
-	"errors"
 	"fmt"
 	"strings"
 
@@ -16,8 +15,9 @@ const permissionKeyPrefix = "joe.permissions."
 
 // Auth implements logic to add user authorization checks to your bot.
 type Auth struct {
-	logger *zap.Logger
-	store  *Storage
+	logger   *zap.Logger
+	store    *Storage
+	fallback ReadFallback // used to read permissions, see WithAuthFallback(…)
 }
 
 // NewAuth creates a new Auth instance.
@@ -45,9 +45,12 @@ func NewAuth(logger *zap.Logger, store *Storage) *Auth {
 // could also allow even more general access to everything in the api via the
 // "api" scope. The empty scope "" cannot be granted and will thus always return
 // an error in the permission check.
+//
+// If the permissions cannot be read from the Memory, the error is returned
+// unless a different ReadFallback was configured via WithAuthFallback(…).
 func (a *Auth) CheckPermission(scope, userID string) error {
 	key := a.permissionsKey(userID)
-	permissions, err := a.loadPermissions(key)
+	permissions, err := a.loadPermissions(key, a.fallback)
 	if err != nil {
 		return err
 	}
@@ -93,7 +96,7 @@ func (a *Auth) UserPermissions(userID string) ([]string, error) {
 	)
 
 	key := a.permissionsKey(userID)
-	permissions, err := a.loadPermissions(key)
+	permissions, err := a.loadPermissions(key, a.fallback)
 	if err != nil {
 		return nil, err
 	}
@@ -101,9 +104,9 @@ func (a *Auth) UserPermissions(userID string) ([]string, error) {
 	return permissions, nil
 }
 
-func (a *Auth) loadPermissions(key string) ([]string, error) {
+func (a *Auth) loadPermissions(key string, fallback ReadFallback) ([]string, error) {
 	var permissions []string
-	ok, err := a.store.Get(key, &permissions)
+	ok, err := a.store.GetWithFallback(key, &permissions, fallback)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load user permissions: %w", err)
 	}
@@ -124,16 +127,16 @@ func (a *Auth) loadPermissions(key string) ([]string, error) {
 // scope multiple times is a safe operation and will not change the internal
 // permissions that are written to the Memory.
 //
-// The empty scope cannot be granted and trying to do so will result in an error.
+// The empty scope cannot be granted and trying to do so will return ErrEmptyScope.
 // If you want to grant access to all scopes you should prefix them with a
 // common scope such as "root." or "api.".
 func (a *Auth) Grant(scope, userID string) (bool, error) {
 	if scope == "" {
-		return false, errors.New("scope cannot be empty")
+		return false, ErrEmptyScope
 	}
 
 	key := a.permissionsKey(userID)
-	oldPermissions, err := a.loadPermissions(key)
+	oldPermissions, err := a.loadPermissions(key, FailOnError) // never write permissions based on a fallback
 	if err != nil {
 		return false, err
 	}
@@ -164,14 +167,15 @@ func (a *Auth) Grant(scope, userID string) (bool, error) {
 // not currently have the revoked scope this function returns false and no error.
 //
 // If you are trying to revoke a permission but the user was previously granted
-// a scope that contains the revoked scope this function returns an error.
+// a scope that contains the revoked scope this function returns an error that
+// wraps ErrMoreGeneralScope.
 func (a *Auth) Revoke(scope, userID string) (bool, error) {
 	if scope == "" {
-		return false, errors.New("scope cannot be empty")
+		return false, ErrEmptyScope
 	}
 
 	key := a.permissionsKey(userID)
-	oldPermissions, err := a.loadPermissions(key)
+	oldPermissions, err := a.loadPermissions(key, FailOnError) // never write permissions based on a fallback
 	if err != nil {
 		return false, err
 	}
@@ -189,7 +193,7 @@ func (a *Auth) Revoke(scope, userID string) (bool, error) {
 		}
 
 		if strings.HasPrefix(scope, p) {
-			return false, fmt.Errorf("cannot revoke scope %q because the user still has the more general scope %q", scope, p)
+			return false, fmt.Errorf("cannot revoke scope %q because %w %q", scope, ErrMoreGeneralScope, p)
 		}
 
 		newPermissions = append(newPermissions, p)
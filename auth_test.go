@@ -137,6 +137,7 @@ func TestAuth_RevokeWiderScope(t *testing.T) {
 
 	ok, err := auth.Revoke("foo.bar", "fgrosse")
 	assert.EqualError(t, err, `cannot revoke scope "foo.bar" because the user still has the more general scope "foo"`)
+	assert.True(t, errors.Is(err, joe.ErrMoreGeneralScope))
 	assert.False(t, ok)
 }
 
@@ -146,7 +147,7 @@ func TestAuth_RevokeEmptyScope(t *testing.T) {
 	auth := joe.NewAuth(logger, store.Storage)
 
 	ok, err := auth.Revoke("", "fgrosse")
-	assert.EqualError(t, err, "scope cannot be empty")
+	assert.Equal(t, joe.ErrEmptyScope, err)
 	assert.False(t, ok)
 }
 
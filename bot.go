@@ -3,13 +3,16 @@ package joe
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
 	"regexp"
 	"strings"
+	"sync"
 	"syscall"
 
+	"github.com/go-joe/joe/reactions"
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -29,8 +32,70 @@ type Bot struct {
 	Auth    *Auth
 	Logger  *zap.Logger
 
-	ctx     context.Context
-	initErr error // any error when we created a new bot
+	ctx              context.Context
+	maxMessageLength int    // used to split paged messages
+	pageNumbers      bool   // if true, paged messages are numbered, see WithPageNumbers()
+	selfMessages     bool   // if true, messages authored by the bot are not ignored
+	ignoreBots       bool   // if true, messages authored by other bots are ignored
+	multiMatch       bool   // if true, all matching message handlers are executed
+	defaultChannel   string // used by Bot.Broadcast(…)
+	initErr          error  // any error when we created a new bot
+
+	localizer     messageLocalizer // passed to each Message, see Message.RespondLocalized(…)
+	conversations *conversations   // passed to each Message, see Message.Await(…)
+
+	preprocessors []func(string) string // applied to the text of all messages, see WithMessagePreprocessor(…)
+	loopGuard     *loopGuard            // optional, see WithLoopGuard(…)
+
+	commandToggles  bool   // if true, commands can be disabled per channel, see WithCommandToggles(…)
+	cooldownMessage string // optional, see WithCooldownMessage(…)
+	auditLog        bool   // if true, authorized commands are recorded, see WithAuditLog(…)
+	auditSeq        uint32 // accessed atomically, used to create unique audit log keys
+	clock           Clock
+
+	storageCleanups []storageCleanup // optional, see WithStorageCleanup(…)
+
+	commandErrorResponder func(Message, error) string // optional, see WithCommandErrorResponder(…)
+
+	mu       sync.Mutex                // protects the commands and patterns
+	commands []CommandInfo             // all message handlers, see Bot.Commands()
+	patterns map[string]*regexp.Regexp // compiled message patterns, see Bot.compilePattern(…)
+}
+
+// CommandInfo describes a message handler that was registered via
+// Bot.Respond(…) or any of its variants.
+type CommandInfo struct {
+	// Pattern is the regular expression of the handler as it is passed to the
+	// Message (see Message.Pattern). For handlers that were registered via
+	// Bot.Respond(…) this is the given message wrapped in "^" and "$".
+	Pattern string
+
+	// Aliases contains the patterns of all other messages that execute the
+	// same handler (see Bot.RespondAliases(…)).
+	Aliases []string
+}
+
+// Name returns the name of the command which is the first word of the literal
+// prefix of its Pattern in lower case (e.g. "deploy" for "^deploy (.+)$"). The
+// name is empty if the Pattern does not start with a literal word (e.g. for
+// "(.+) is great"). Adapters can use the name to register the command at the
+// chat (see CommandSyncer).
+func (c CommandInfo) Name() string {
+	expr := strings.TrimPrefix(c.Pattern, "^")
+	expr = strings.TrimPrefix(expr, "(?i)")
+
+	regex, err := regexp.Compile(expr)
+	if err != nil {
+		return ""
+	}
+
+	prefix, _ := regex.LiteralPrefix()
+	fields := strings.Fields(prefix)
+	if len(fields) == 0 || strings.HasPrefix(prefix, " ") {
+		return ""
+	}
+
+	return strings.ToLower(fields[0])
 }
 
 // A Module is an optional Bot extension that can add new capabilities such as
@@ -57,9 +122,10 @@ func (f ModuleFunc) Apply(conf *Config) error {
 // different context. All Options are available as functions in this package
 // that start with "With…".
 //
-// If there was an error initializing a Module it is stored and returned on the
-// next call to Bot.Run(). Before you start the bot however you should register
-// your custom event handlers.
+// If there was an error initializing a Module or if the resulting configuration
+// is invalid, the error is stored and returned on the next call to Bot.Run().
+// Before you start the bot however you should register your custom event
+// handlers.
 //
 // Example:
 //   b := joe.New("example",
@@ -84,6 +150,7 @@ func New(name string, modules ...Module) *Bot {
 	conf.Context = ctx
 	conf.Name = name
 	conf.HandlerTimeout = brain.handlerTimeout
+	conf.MaxMessageLength = DefaultMaxMessageLength
 
 	logger.Info("Initializing bot", zap.String("name", name))
 	for _, mod := range modules {
@@ -93,25 +160,147 @@ func New(name string, modules ...Module) *Bot {
 		}
 	}
 
+	conf.errs = append(conf.errs, conf.validate(modules)...)
+
+	if sc, ok := ctx.(signalContext); ok {
+		if _, ok := conf.Context.(signalContext); !ok {
+			// A module replaced the default context so nobody would ever stop
+			// listening for signals.
+			sc.stop()
+		}
+	}
+
 	// apply all configuration options
 	brain.handlerTimeout = conf.HandlerTimeout
+	brain.clock = conf.Clock()
+	brain.observers = conf.observers
+	brain.tracer = conf.tracer
+	brain.errorPolicy = conf.errPolicy
+	if conf.EventQueueLimit > 0 {
+		brain.queueSlots = make(chan struct{}, conf.EventQueueLimit)
+	}
+
+	if conf.sendRetryAttempts > 0 && conf.adapter != nil {
+		r := &retrier{
+			ctx:      conf.Context,
+			closing:  brain.closing,
+			logger:   conf.Logger("adapter"),
+			clock:    conf.Clock(),
+			attempts: conf.sendRetryAttempts,
+			backoff:  conf.sendRetryBackoff,
+		}
+
+		conf.adapter = &wrappedAdapter{Adapter: conf.adapter, wrap: r.retry}
+	}
+
+	if len(conf.observers) > 0 && conf.adapter != nil {
+		wrap := observeSends(conf.observers, conf.Clock())
+		conf.adapter = &wrappedAdapter{Adapter: conf.adapter, wrap: wrap}
+	}
 
-	return &Bot{
-		Name:    conf.Name,
-		ctx:     conf.Context,
-		Logger:  conf.logger,
-		Adapter: conf.adapter,
-		Auth:    NewAuth(conf.logger, store),
-		Brain:   brain,
-		Store:   store,
-		initErr: multierr.Combine(conf.errs...),
+	var guard *loopGuard
+	if conf.loopGuardWindow > 0 && conf.adapter != nil {
+		guard = newLoopGuard(conf.Clock(), conf.loopGuardWindow)
+		conf.adapter = &wrappedAdapter{Adapter: conf.adapter, wrap: guard.wrap}
+	}
+
+	if conf.memoryCacheTTL > 0 {
+		cache := NewCachingMemory(store.memory, conf.memoryCacheTTL, conf.memoryCacheSize)
+		cache.clock = conf.Clock()
+		store.SetMemory(cache)
+	}
+
+	auth := NewAuth(conf.logger, store)
+	auth.fallback = conf.authRead
+
+	conversations := newConversations(conf.Clock(), conf.confirmYes, conf.confirmNo)
+	brain.intercept = conversations.intercept
+
+	b := &Bot{
+		Name:                  conf.Name,
+		ctx:                   conf.Context,
+		Logger:                conf.logger,
+		Adapter:               conf.adapter,
+		Auth:                  auth,
+		Brain:                 brain,
+		Store:                 store,
+		maxMessageLength:      conf.MaxMessageLength,
+		pageNumbers:           conf.PageNumbers,
+		selfMessages:          conf.SelfMessages,
+		ignoreBots:            conf.IgnoreBots,
+		multiMatch:            conf.MultiMatch,
+		defaultChannel:        conf.DefaultChannel,
+		conversations:         conversations,
+		preprocessors:         conf.preprocessors,
+		loopGuard:             guard,
+		commandToggles:        conf.commandToggles,
+		cooldownMessage:       conf.cooldownMessage,
+		auditLog:              conf.auditLog,
+		storageCleanups:       conf.storageCleanups,
+		commandErrorResponder: conf.commandErrorResponder,
+		clock:                 conf.Clock(),
+		initErr:               multierr.Combine(conf.errs...),
+		localizer: messageLocalizer{
+			localizer:     conf.localizer,
+			defaultLocale: conf.defaultLocale,
+			store:         store,
+		},
+	}
+
+	if guard != nil {
+		b.registerLoopGuard()
+	}
+
+	if conf.commandToggles {
+		b.registerCommandToggles(conf.commandTogglesScope)
+	}
+
+	for _, register := range conf.responders {
+		register(b)
+	}
+
+	return b
+}
+
+// NewE is like New(…) but any error that occurs when initializing a Module or
+// validating the resulting configuration is returned immediately instead of on
+// the next call to Bot.Run(). This lets programs fail fast during setup. Errors
+// of the event handlers are still returned by Bot.Run() since handlers are
+// typically registered after the Bot was created. If an error is returned, the
+// Adapter and Memory that have been set up by the modules are closed already.
+func NewE(name string, modules ...Module) (*Bot, error) {
+	b := New(name, modules...)
+	if b.initErr != nil {
+		b.release()
+		return nil, fmt.Errorf("failed to initialize bot: %w", b.initErr)
+	}
+
+	return b, nil
+}
+
+// release frees all resources of a bot that will never run. This closes the
+// Adapter and the Memory, which may have been connected by the modules, and
+// stops listening for signals if the bot uses the default context.
+func (b *Bot) release() {
+	if ctx, ok := b.ctx.(signalContext); ok {
+		ctx.stop()
+	}
+
+	err := b.Adapter.Close()
+	if err != nil {
+		b.Logger.Info("Error while closing adapter", zap.Error(err))
+	}
+
+	err = b.Store.Close()
+	if err != nil {
+		b.Logger.Info("Error while closing memory", zap.Error(err))
 	}
 }
 
 func newContext(modules []Module) context.Context {
 	var conf Config
 	for _, mod := range modules {
-		if x, ok := mod.(loggerModule); ok {
+		if x, ok := mod.(contextModule); ok {
 			_ = x(&conf)
 		}
 	}
@@ -127,14 +316,29 @@ func newContext(modules []Module) context.Context {
 // This context is canceled if the bot receives a SIGINT, SIGQUIT or SIGTERM.
 func cliContext() context.Context {
 	ctx, cancel := context.WithCancel(context.Background())
-	sig := make(chan os.Signal)
+	sig := make(chan os.Signal, 1)
 	signal.Notify(sig, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGTERM)
 	go func() {
-		<-sig
+		select {
+		case <-sig:
+		case <-ctx.Done():
+		}
 		cancel()
 	}()
 
-	return ctx
+	stop := func() {
+		signal.Stop(sig)
+		cancel()
+	}
+
+	return signalContext{Context: ctx, stop: stop}
+}
+
+// signalContext marks a context that is only canceled by a signal so the bot
+// can report the ShutdownReason.
+type signalContext struct {
+	context.Context
+	stop func() // stops listening for signals and cancels the context
 }
 
 func newLogger(modules []Module) *zap.Logger {
@@ -176,11 +380,50 @@ func newLogger(modules []Module) *zap.Logger {
 	return logger
 }
 
+// Context returns the context of the bot. The context is canceled when the bot
+// shuts down (by default via SIGINT, SIGQUIT or SIGTERM) so it can be used to
+// stop any goroutines that have been spawned by handlers or modules. Modules
+// can access the same context during setup via the Config.Context field.
+func (b *Bot) Context() context.Context {
+	return b.ctx
+}
+
+// EventEmitter returns the EventEmitter of the bot (i.e. its Brain). Code that
+// only needs to emit events can depend on this interface instead of the Brain
+// which makes it easy to inject a fake implementation in tests.
+func (b *Bot) EventEmitter() EventEmitter {
+	return b.Brain
+}
+
 // Run starts the bot and runs its event handler loop until the bots context
 // is canceled (by default via SIGINT, SIGQUIT or SIGTERM). If there was an
 // an error when setting up the Bot via New() or when registering the event
 // handlers it will be returned immediately.
 func (b *Bot) Run() error {
+	// A second signal stops waiting for pending events.
+	return b.run(b.ctx, cliContext)
+}
+
+// RunContext is like Bot.Run() but the bot runs until the given context or the
+// context of the bot is canceled. This is useful if the bot is embedded in a
+// service that manages its own context (e.g. via errgroup) and the context is
+// only available after the bot was created. Note that by default the context of
+// the bot is canceled via SIGINT, SIGQUIT or SIGTERM so these signals still
+// stop the bot. Use the WithContext(…) option if the service handles signals
+// itself.
+//
+// When either context is canceled, the bot still processes all pending events
+// before RunContext returns. Use Bot.Drain(…) if you need to limit how long the
+// bot waits for them. Note that Bot.Context() still returns the context of the
+// bot which is not canceled together with the given context.
+func (b *Bot) RunContext(ctx context.Context) error {
+	return b.run(ctx, context.Background)
+}
+
+// run starts the bot and shuts it down when ctx or the context of the bot is
+// done. The shutdown context is created afterwards and limits how long we wait
+// for pending events.
+func (b *Bot) run(ctx context.Context, shutdownContext func() context.Context) error {
 	if b.initErr != nil {
 		return fmt.Errorf("failed to initialize bot: %w", b.initErr)
 	}
@@ -190,15 +433,42 @@ func (b *Bot) Run() error {
 		return fmt.Errorf("invalid event handlers: %w", errs)
 	}
 
+	b.Brain.handleEvent(context.Background(), Event{Data: ModulesReadyEvent{}})
+
+	if syncer, ok := b.Adapter.(CommandSyncer); ok && b.AdapterSupports(CapabilityCommands) {
+		err := syncer.SyncCommands(b.Commands())
+		if err != nil {
+			return fmt.Errorf("failed to sync commands: %w", err)
+		}
+	}
+
 	b.Adapter.RegisterAt(b.Brain)
 
 	go func() {
-		// Keep running until the context is canceled via SIGINT.
-		<-b.ctx.Done()
-		shutdownCtx := cliContext() // closed upon another SIGINT
-		b.Brain.Shutdown(shutdownCtx)
+		// Keep running until the context is canceled (e.g. via SIGINT).
+		done := ctx
+		select {
+		case <-ctx.Done():
+		case <-b.ctx.Done():
+			done = b.ctx
+		}
+
+		reason := ShutdownContext
+		if _, ok := done.(signalContext); ok {
+			reason = ShutdownSignal
+		}
+
+		b.Brain.shutdownWithReason(shutdownContext(), reason)
 	}()
 
+	// The cleanups stop as soon as the bot stops handling events, even if the
+	// bot was stopped without canceling the context (e.g. via Bot.Drain(…)).
+	cleanupCtx, stopCleanups := context.WithCancel(ctx)
+	defer stopCleanups()
+	for _, cleanup := range b.storageCleanups {
+		go b.runStorageCleanup(cleanupCtx, cleanup)
+	}
+
 	b.Logger.Info("Bot initialized and ready to operate", zap.String("name", b.Name))
 	b.Brain.HandleEvents()
 
@@ -216,6 +486,28 @@ func (b *Bot) Run() error {
 	return nil
 }
 
+// Drain gracefully shuts down a running bot. Other than canceling the context
+// of the bot, Drain first stops the Adapter from emitting new events if it
+// implements the Drainer interface. Afterwards it shuts down the Brain which
+// processes all pending events before Bot.Run() returns. The context can be
+// used to limit how long Drain waits for the Adapter and the pending events.
+//
+// If the Adapter fails to stop emitting events, the Brain is still shut down
+// and the error of the Adapter is returned.
+func (b *Bot) Drain(ctx context.Context) error {
+	var err error
+	if drainer, ok := b.Adapter.(Drainer); ok && b.AdapterSupports(CapabilityDrain) {
+		b.Logger.Info("Draining adapter")
+		err = drainer.Drain(ctx)
+		if err != nil {
+			err = fmt.Errorf("failed to drain adapter: %w", err)
+		}
+	}
+
+	b.Brain.shutdownWithReason(ctx, ShutdownDrain)
+	return err
+}
+
 // Respond registers an event handler that listens for the ReceiveMessageEvent
 // and executes the given function only if the message text matches the given
 // message. The message will be matched against the msg string as regular
@@ -231,7 +523,9 @@ func (b *Bot) Run() error {
 // instances.
 //
 // If multiple matching patterns are registered, only the first registered
-// handler is executed.
+// handler is executed. Note that this also skips any other event handlers for
+// the ReceiveMessageEvent that have been registered after the matching handler.
+// You can use the WithMultiMatch() option to execute all matching handlers.
 func (b *Bot) Respond(msg string, fun func(Message) error) {
 	expr := "^" + msg + "$"
 	b.RespondRegex(expr, fun)
@@ -239,12 +533,150 @@ func (b *Bot) Respond(msg string, fun func(Message) error) {
 
 // RespondRegex is like Bot.Respond(…) but gives a little more control over the
 // regular expression. However, also with this function messages are matched in
-// a case insensitive way.
+// a case insensitive way. An empty expression is invalid and is reported as
+// error on the next call to Bot.Run().
 func (b *Bot) RespondRegex(expr string, fun func(Message) error) {
-	if expr == "" {
+	b.respondCommand(expr, nil, b.messageHandler(expr, fun))
+}
+
+// RespondEvent is like Bot.Respond(…) but instead of a Message, the given
+// function receives the handler context, the full ReceiveMessageEvent and all
+// sub matches of the pattern. This is useful if you need access to the raw
+// event without the Message abstraction.
+func (b *Bot) RespondEvent(msg string, fun func(ctx context.Context, evt ReceiveMessageEvent, matches []string) error) {
+	expr := "^" + msg + "$"
+	b.respondCommand(expr, nil, fun)
+}
+
+// RespondInChannels is like Bot.Respond(…) but the handler is only executed for
+// messages that were received in one of the given channels. Messages from any
+// other channel are ignored by this handler but are still passed to all other
+// registered handlers.
+func (b *Bot) RespondInChannels(channels []string, msg string, fun func(Message) error) {
+	allowed := channelSet(channels)
+	expr := "^" + msg + "$"
+	b.respondCommand(expr, func(evt ReceiveMessageEvent) bool {
+		return allowed[evt.Channel]
+	}, b.messageHandler(expr, fun))
+}
+
+// RespondExceptChannels is like Bot.Respond(…) but the handler is never executed
+// for messages that were received in one of the given channels. Messages from
+// those channels are still passed to all other registered handlers.
+func (b *Bot) RespondExceptChannels(channels []string, msg string, fun func(Message) error) {
+	denied := channelSet(channels)
+	expr := "^" + msg + "$"
+	b.respondCommand(expr, func(evt ReceiveMessageEvent) bool {
+		return !denied[evt.Channel]
+	}, b.messageHandler(expr, fun))
+}
+
+// RespondAliases is like Bot.Respond(…) but the handler is executed for
+// messages that match any of the given patterns (e.g. "deploy (.+)" and
+// "ship (.+)"). Each pattern is matched separately so the aliases can have
+// different sub matches and Message.Pattern contains the pattern that matched.
+// All patterns are reported as a single command by Bot.Commands() where the
+// first pattern is the CommandInfo.Pattern and the others are its Aliases. If
+// any of the patterns is invalid, none of them is registered and the error is
+// returned on the next call to Bot.Run().
+func (b *Bot) RespondAliases(msgs []string, fun func(Message) error) {
+	if len(msgs) == 0 {
+		b.addRegistrationErr(errors.New("command aliases need at least one message pattern"))
 		return
 	}
 
+	exprs := make([]string, len(msgs))
+	for i, msg := range msgs {
+		exprs[i] = "^" + msg + "$"
+
+		// All patterns are validated first so we never register only some of
+		// the aliases.
+		_, err := b.messagePattern(exprs[i])
+		if err != nil {
+			b.addRegistrationErr(err)
+			return
+		}
+	}
+
+	cmd := CommandInfo{Pattern: exprs[0]}
+	for _, expr := range exprs {
+		if !b.respondRegex(expr, cmd.Name(), nil, b.messageHandler(expr, fun)) {
+			return
+		}
+	}
+
+	if len(exprs) > 1 {
+		cmd.Aliases = exprs[1:]
+	}
+
+	b.addCommand(cmd)
+}
+
+func channelSet(channels []string) map[string]bool {
+	set := make(map[string]bool, len(channels))
+	for _, c := range channels {
+		set[c] = true
+	}
+
+	return set
+}
+
+// messageHandler wraps a function that accepts a Message so it can be passed
+// to Bot.respondRegex(…). The pattern is passed to the Message as it was given
+// by the caller.
+func (b *Bot) messageHandler(pattern string, fun func(Message) error) func(context.Context, ReceiveMessageEvent, []string) error {
+	return func(ctx context.Context, evt ReceiveMessageEvent, matches []string) error {
+		return fun(b.newMessage(ctx, evt, pattern, matches))
+	}
+}
+
+// newMessage creates the Message that is passed to the message handlers.
+func (b *Bot) newMessage(ctx context.Context, evt ReceiveMessageEvent, pattern string, matches []string) Message {
+	return Message{
+		Context:  ctx,
+		ID:       evt.ID,
+		Text:     evt.Text,
+		AuthorID: evt.AuthorID,
+		Data:     evt.Data,
+		Channel:  evt.Channel,
+		Matches:  matches,
+		Pattern:  pattern,
+
+		AuthorIsBot: evt.AuthorIsBot,
+		ThreadID:    evt.ThreadID,
+		Attachments: evt.Attachments,
+
+		adapter:       b.Adapter,
+		maxLen:        b.maxMessageLength,
+		pageNumbers:   b.pageNumbers,
+		localizer:     b.localizer,
+		conversations: b.conversations,
+	}
+}
+
+// respondCommand registers a message handler via Bot.respondRegex(…) and
+// records it as command of the bot.
+func (b *Bot) respondCommand(expr string, accept func(ReceiveMessageEvent) bool, fun func(context.Context, ReceiveMessageEvent, []string) error) {
+	cmd := CommandInfo{Pattern: expr}
+	if b.respondRegex(expr, cmd.Name(), accept, fun) {
+		b.addCommand(cmd)
+	}
+}
+
+// addCommand records a command so it is returned by Bot.Commands().
+func (b *Bot) addCommand(cmd CommandInfo) {
+	b.mu.Lock()
+	b.commands = append(b.commands, cmd)
+	b.mu.Unlock()
+}
+
+// messagePattern returns the compiled regular expression of a message handler
+// which always matches in a case insensitive way.
+func (b *Bot) messagePattern(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, errors.New("message pattern must not be empty")
+	}
+
 	if expr[0] == '^' {
 		// String starts with the "^" anchor but does it also have the prefix
 		// or case insensitive matching?
@@ -259,36 +691,185 @@ func (b *Bot) RespondRegex(expr string, fun func(Message) error) {
 		}
 	}
 
-	regex, err := regexp.Compile(expr)
+	return b.compilePattern(expr)
+}
+
+// addRegistrationErr records an error of a message handler together with the
+// location where it was registered so it is returned on the next call to
+// Bot.Run().
+func (b *Bot) addRegistrationErr(err error) {
+	caller := firstExternalCaller()
+	err = fmt.Errorf("%s: %w", caller, err)
+	b.Brain.registrationErrs = append(b.Brain.registrationErrs, err)
+}
+
+// respondRegex registers a ReceiveMessageEvent handler for the given regular
+// expression. If accept is not nil, it is called for each received message and
+// the handler is skipped if it returns false. The handler is also skipped if
+// the command with the given name was disabled in the channel of the message
+// (see WithCommandToggles(…)). It returns false if the expression is invalid,
+// in which case the error is returned on the next call to Bot.Run().
+func (b *Bot) respondRegex(expr, command string, accept func(ReceiveMessageEvent) bool, fun func(context.Context, ReceiveMessageEvent, []string) error) bool {
+	pattern := expr // as it was given by the caller, see Message.Pattern
+	regex, err := b.messagePattern(expr)
 	if err != nil {
-		caller := firstExternalCaller()
-		err = fmt.Errorf("%s: %w", caller, err)
-		b.Brain.registrationErrs = append(b.Brain.registrationErrs, err)
-		return
+		b.addRegistrationErr(err)
+		return false
 	}
 
 	b.Brain.RegisterHandler(func(ctx context.Context, evt ReceiveMessageEvent) error {
-		matches := regex.FindStringSubmatch(evt.Text)
+		if accept != nil && !accept(evt) {
+			return nil
+		}
+
+		if b.isSelfMessage(evt) {
+			return nil
+		}
+
+		if b.ignoreBots && evt.AuthorIsBot {
+			return nil
+		}
+
+		matches := regex.FindStringSubmatch(b.preprocess(evt.Text))
 		if len(matches) == 0 {
 			return nil
 		}
 
+		if b.commandToggles && !b.commandEnabled(evt.Channel, command) {
+			return nil
+		}
+
 		// If the event text matches our regular expression we can already mark
 		// the event context as done so the Brain does not run any other handlers
 		// that might match the received message.
-		FinishEventContent(ctx)
-
-		return fun(Message{
-			Context:  ctx,
-			ID:       evt.ID,
-			Text:     evt.Text,
-			AuthorID: evt.AuthorID,
-			Data:     evt.Data,
-			Channel:  evt.Channel,
-			Matches:  matches[1:],
-			adapter:  b.Adapter,
-		})
+		if !b.multiMatch {
+			FinishEventContent(ctx)
+		}
+
+		return b.executeCommand(ctx, evt, pattern, matches[1:], fun)
 	})
+
+	return true
+}
+
+// executeCommand runs the handler of a matching message. If the handler fails,
+// the author of the message is notified (see WithCommandErrorResponder(…)).
+// This is why panics are already recovered here instead of in the Brain. Either
+// way, the error is returned so the Brain logs it.
+func (b *Bot) executeCommand(ctx context.Context, evt ReceiveMessageEvent, pattern string, matches []string, fun func(context.Context, ReceiveMessageEvent, []string) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panic: %v", r)
+		}
+
+		if err == nil || b.commandErrorResponder == nil {
+			return
+		}
+
+		text := b.commandErrorResponder(b.newMessage(ctx, evt, pattern, matches), err)
+		if text == "" {
+			return
+		}
+
+		sendErr := b.Adapter.Send(text, evt.Channel)
+		if sendErr != nil {
+			b.Logger.Error("Failed to send command error message",
+				zap.String("channel", evt.Channel),
+				zap.Error(sendErr),
+			)
+		}
+	}()
+
+	return fun(ctx, evt, matches)
+}
+
+// compilePattern returns the compiled regular expression for expr, using the
+// cached version if the bot compiled the same expression before. This way
+// registering the same pattern many times (e.g. by a plugin that registers its
+// commands dynamically) compiles it only once. A *regexp.Regexp is safe for
+// concurrent use so it can be shared between handlers. The cache is scoped to
+// the bot so it never holds more patterns than the bot has handlers.
+func (b *Bot) compilePattern(expr string) (*regexp.Regexp, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if regex, ok := b.patterns[expr]; ok {
+		return regex, nil
+	}
+
+	regex, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.patterns == nil {
+		b.patterns = map[string]*regexp.Regexp{}
+	}
+
+	b.patterns[expr] = regex
+	return regex, nil
+}
+
+// Commands returns all message handlers that have been registered via
+// Bot.Respond(…) or any of its variants in the order in which they have been
+// registered. This can be used to list the commands of the bot (e.g. in a help
+// message or a web UI).
+func (b *Bot) Commands() []CommandInfo {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]CommandInfo(nil), b.commands...)
+}
+
+// preprocess applies all preprocessors of the bot to the given message text.
+func (b *Bot) preprocess(text string) string {
+	for _, fun := range b.preprocessors {
+		text = fun(text)
+	}
+
+	return text
+}
+
+// NormalizeText can be passed to WithMessagePreprocessor(…) to normalize the
+// text of received messages. It replaces typographic ("smart") quotes with
+// their ASCII counterparts, collapses all consecutive whitespace (including
+// line breaks) into a single space and trims the text.
+func NormalizeText(text string) string {
+	text = smartQuotes.Replace(text)
+	return strings.Join(strings.Fields(text), " ")
+}
+
+var smartQuotes = strings.NewReplacer(
+	"\u2018", "'", // left single quotation mark
+	"\u2019", "'", // right single quotation mark
+	"\u201C", `"`, // left double quotation mark
+	"\u201D", `"`, // right double quotation mark
+)
+
+// isSelfMessage returns true if the given event was authored by the bot itself
+// and should thus be ignored by the message handlers.
+func (b *Bot) isSelfMessage(evt ReceiveMessageEvent) bool {
+	if b.selfMessages || evt.AuthorID == "" {
+		return false
+	}
+
+	adapter, ok := b.Adapter.(SelfAwareAdapter)
+	if !ok || !b.AdapterSupports(CapabilitySelfAware) {
+		return false
+	}
+
+	return evt.AuthorID == adapter.BotUserID()
+}
+
+// AdapterSupports returns true if the Adapter of the bot supports the given
+// Capability (e.g. CapabilityReactions).
+func (b *Bot) AdapterSupports(c Capability) bool {
+	return AdapterSupports(b.Adapter, c)
+}
+
+// AdapterCapabilities returns all Capabilities of the Adapter of the bot.
+func (b *Bot) AdapterCapabilities() []Capability {
+	return AdapterCapabilities(b.Adapter)
 }
 
 // Say is a helper function to makes the Bot output the message via its Adapter
@@ -304,3 +885,69 @@ func (b *Bot) Say(channel, msg string, args ...interface{}) {
 		b.Logger.Error("Failed to send message", zap.Error(err))
 	}
 }
+
+// React attaches the given reaction to the message with the given ID in the
+// given channel. Other than Message.React(…) this can be used to react to any
+// message, including messages that were sent by the bot itself. If the Adapter
+// does not implement the ReactByIDAdapter interface, ErrNotImplemented is
+// returned.
+func (b *Bot) React(channel, messageID string, r reactions.Reaction) error {
+	adapter, ok := b.Adapter.(ReactByIDAdapter)
+	if !ok || !b.AdapterSupports(CapabilityReactByID) {
+		return ErrNotImplemented
+	}
+
+	return adapter.ReactID(r, channel, messageID)
+}
+
+// UserPresence returns whether the user with the given ID is currently online.
+// If the Adapter does not implement the PresenceAdapter interface,
+// ErrNotImplemented is returned.
+func (b *Bot) UserPresence(userID string) (Presence, error) {
+	adapter, ok := b.Adapter.(PresenceAdapter)
+	if !ok || !b.AdapterSupports(CapabilityPresence) {
+		return "", ErrNotImplemented
+	}
+
+	return adapter.Presence(userID)
+}
+
+// Channels returns all channels the bot is a member of. If the Adapter does not
+// implement the ChannelLister interface, ErrNotImplemented is returned.
+func (b *Bot) Channels() ([]Channel, error) {
+	adapter, ok := b.Adapter.(ChannelLister)
+	if !ok || !b.AdapterSupports(CapabilityChannels) {
+		return nil, ErrNotImplemented
+	}
+
+	return adapter.Channels()
+}
+
+// ChannelMembers returns all members of the given channel (e.g. to notify all
+// of them). If the Adapter does not implement the MemberLister interface,
+// ErrNotImplemented is returned.
+func (b *Bot) ChannelMembers(channel string) ([]User, error) {
+	adapter, ok := b.Adapter.(MemberLister)
+	if !ok || !b.AdapterSupports(CapabilityMembers) {
+		return nil, ErrNotImplemented
+	}
+
+	return adapter.Members(channel)
+}
+
+// Broadcast is like Bot.Say(…) but it sends the message to the default channel
+// of the bot (see WithDefaultChannel(…)). This is useful for bots that mainly
+// operate in a single channel (e.g. to send notifications). Other than
+// Bot.Say(…), any error is returned instead of being logged. If the bot has no
+// default channel, ErrNoDefaultChannel is returned.
+func (b *Bot) Broadcast(msg string, args ...interface{}) error {
+	if b.defaultChannel == "" {
+		return ErrNoDefaultChannel
+	}
+
+	if len(args) > 0 {
+		msg = fmt.Sprintf(msg, args...)
+	}
+
+	return b.Adapter.Send(msg, b.defaultChannel)
+}
@@ -2,13 +2,19 @@ package joe_test
 
 import (
 	"bytes"
+	"context"
 	"errors"
+	"fmt"
 	"io"
+	"io/ioutil"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/go-joe/joe"
 	"github.com/go-joe/joe/joetest"
+	"github.com/go-joe/joe/reactions"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -29,6 +35,19 @@ func TestBot_New(t *testing.T) {
 	require.NotNil(t, b.Adapter)
 }
 
+func TestBot_Context(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := joetest.NewBot(t, joe.WithContext(ctx))
+	assert.Equal(t, ctx, b.Context())
+}
+
+func TestBot_EventEmitter(t *testing.T) {
+	b := joetest.NewBot(t)
+	assert.Equal(t, b.Brain, b.EventEmitter())
+}
+
 func TestBot_Run(t *testing.T) {
 	b := joetest.NewBot(t)
 
@@ -39,6 +58,7 @@ func TestBot_Run(t *testing.T) {
 
 	shutdownEvt := make(chan bool)
 	b.Brain.RegisterHandler(func(evt joe.ShutdownEvent) {
+		assert.Equal(t, joe.ShutdownRequested, evt.Reason)
 		shutdownEvt <- true
 	})
 
@@ -55,6 +75,83 @@ func TestBot_Run(t *testing.T) {
 	wait(t, runExit)
 }
 
+func TestBot_RunContext(t *testing.T) {
+	b := joetest.NewBot(t)
+
+	initEvt := make(chan bool)
+	b.Brain.RegisterHandler(func(evt joe.InitEvent) {
+		initEvt <- true
+	})
+
+	shutdownEvt := make(chan bool)
+	b.Brain.RegisterHandler(func(evt joe.ShutdownEvent) {
+		assert.Equal(t, joe.ShutdownContext, evt.Reason)
+		shutdownEvt <- true
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runExit := make(chan bool)
+	go func() {
+		assert.NoError(t, b.RunContext(ctx))
+		runExit <- true
+	}()
+
+	wait(t, initEvt)
+	cancel()
+
+	wait(t, shutdownEvt)
+	wait(t, runExit)
+}
+
+func TestBot_RunContext_BotContext(t *testing.T) {
+	// The context of the bot is canceled via signals by default so it must
+	// also stop a bot that was started with a different context.
+	botCtx, cancel := context.WithCancel(context.Background())
+	b := joetest.NewBot(t, joe.WithContext(botCtx))
+
+	initEvt := make(chan bool)
+	b.Brain.RegisterHandler(func(evt joe.InitEvent) {
+		initEvt <- true
+	})
+
+	runExit := make(chan bool)
+	go func() {
+		assert.NoError(t, b.RunContext(context.Background()))
+		runExit <- true
+	}()
+
+	wait(t, initEvt)
+	cancel()
+	wait(t, runExit)
+}
+
+func TestBot_Run_ModulesReadyEvent(t *testing.T) {
+	sequence := make(chan string, 2)
+	mod := joe.ModuleFunc(func(conf *joe.Config) error {
+		conf.RegisterHandler(func(joe.ModulesReadyEvent) {
+			sequence <- "modules ready"
+		})
+		return nil
+	})
+
+	b := joetest.NewBot(t, mod)
+	b.Brain.RegisterHandler(func(joe.InitEvent) {
+		sequence <- "init"
+	})
+
+	b.Start()
+	b.Stop()
+
+	assert.Equal(t, "modules ready", <-sequence)
+	assert.Equal(t, "init", <-sequence)
+}
+
+func TestBot_RunContext_InitError(t *testing.T) {
+	b := joetest.NewBot(t, joe.WithEventQueueLimit(-1))
+	err := b.RunContext(context.Background())
+	assert.EqualError(t, err, "failed to initialize bot: event queue limit must not be negative")
+}
+
 func TestBot_Respond(t *testing.T) {
 	b := joetest.NewBot(t)
 	handledMessages := make(chan joe.Message)
@@ -67,20 +164,83 @@ func TestBot_Respond(t *testing.T) {
 	defer b.Stop()
 
 	b.Brain.Emit(joe.ReceiveMessageEvent{
-		Text:    "Hello world, this is a test",
-		Channel: "XXX",
+		Text:     "Hello world, this is a test",
+		Channel:  "XXX",
+		ThreadID: "1234",
 	})
 
 	select {
 	case msg := <-handledMessages:
 		assert.Equal(t, "Hello world, this is a test", msg.Text)
 		assert.Equal(t, "XXX", msg.Channel)
+		assert.Equal(t, "1234", msg.ThreadID)
 		assert.Equal(t, []string{"world", "test"}, msg.Matches)
 	case <-time.After(time.Second):
 		t.Error("Timeout")
 	}
 }
 
+func TestBot_Respond_MessagePreprocessor(t *testing.T) {
+	b := joetest.NewBot(t,
+		joe.WithMessagePreprocessor(joe.NormalizeText),
+		joe.WithMessagePreprocessor(strings.ToLower),
+	)
+
+	handledMessages := make(chan joe.Message, 1)
+	b.Respond(`say "(.+)"`, func(msg joe.Message) error {
+		handledMessages <- msg
+		return nil
+	})
+
+	b.Start()
+	defer b.Stop()
+
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "  SAY\r\n\u201CHello  World\u201D "})
+
+	select {
+	case msg := <-handledMessages:
+		assert.Equal(t, "  SAY\r\n\u201CHello  World\u201D ", msg.Text, "should keep the original text")
+		assert.Equal(t, []string{"hello world"}, msg.Matches)
+	default:
+		t.Error("message was not handled")
+	}
+}
+
+func TestNormalizeText(t *testing.T) {
+	cases := map[string]string{
+		"":                                      "",
+		"  hello \t world\r\n":                  "hello world",
+		"multi\nline\r\nmessage":                "multi line message",
+		"\u2018single\u2019 \u201Cdouble\u201D": `'single' "double"`,
+	}
+
+	for input, expected := range cases {
+		assert.Equal(t, expected, joe.NormalizeText(input), "%q", input)
+	}
+}
+
+func TestBot_Respond_Deadline(t *testing.T) {
+	b := joetest.NewBot(t, joe.WithHandlerTimeout(time.Hour))
+
+	deadlines := make(chan time.Time, 1)
+	b.Respond("hello", func(msg joe.Message) error {
+		deadline, ok := msg.Deadline()
+		assert.True(t, ok)
+		deadlines <- deadline
+		return nil
+	})
+
+	b.Start()
+	defer b.Stop()
+
+	start := time.Now()
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "hello"})
+
+	deadline := <-deadlines
+	assert.WithinDuration(t, start.Add(time.Hour), deadline, time.Second,
+		"message context should carry the deadline of the handler timeout")
+}
+
 func TestBot_Respond_Data(t *testing.T) {
 	b := joetest.NewBot(t)
 	handledMessages := make(chan joe.Message)
@@ -164,6 +324,36 @@ func TestBot_Respond_Matches(t *testing.T) {
 	}
 }
 
+func TestBot_Respond_Pattern(t *testing.T) {
+	b := joetest.NewBot(t)
+	handledMessages := make(chan joe.Message, 1)
+	handler := func(msg joe.Message) error {
+		handledMessages <- msg
+		return nil
+	}
+
+	b.Respond("ping", handler)
+	b.RespondRegex(`(?i)remember (.+)`, handler)
+
+	b.Start()
+	defer b.Stop()
+
+	cases := map[string]string{ // maps input to expected pattern
+		"PING":                "^ping$",
+		"please remember foo": `(?i)remember (.+)`,
+	}
+
+	for input, pattern := range cases {
+		b.EmitSync(joe.ReceiveMessageEvent{Text: input})
+		select {
+		case msg := <-handledMessages:
+			assert.Equal(t, pattern, msg.Pattern)
+		case <-time.After(time.Second):
+			t.Errorf("timeout: %s", input)
+		}
+	}
+}
+
 func TestBot_Respond_No_Matches(t *testing.T) {
 	b := joetest.NewBot(t)
 	b.Respond("Hello world, this is a test", func(msg joe.Message) error {
@@ -220,156 +410,743 @@ func TestBot_Respond_MultipleMatchingHandlers(t *testing.T) {
 	assert.True(t, secondHandlerExecuted, "second handler should have been executed")
 }
 
-func TestBot_RespondRegex(t *testing.T) {
-	b := joetest.NewBot(t)
-	handledMessages := make(chan joe.Message, 1)
-	b.RespondRegex(`name is ([^\s]+)$`, func(msg joe.Message) error {
-		t.Logf("Received joe.Message %q", msg.Text)
-		handledMessages <- msg
+func TestBot_Respond_MultiMatch(t *testing.T) {
+	b := joetest.NewBot(t, joe.WithMultiMatch())
+
+	var firstHandlerExecuted, secondHandlerExecuted bool
+	b.Respond("hello", func(msg joe.Message) error {
+		firstHandlerExecuted = true
+		return nil
+	})
+
+	b.Respond(".*", func(msg joe.Message) error {
+		secondHandlerExecuted = true
 		return nil
 	})
 
 	b.Start()
 	defer b.Stop()
 
-	cases := map[string][]string{ // maps input to expected matches
-		"name is Joe":                       {"Joe"}, // simple case
-		"NAME IS Joe":                       {"Joe"}, // simple case, case insensitive
-		"Hello, my name is Joe":             {"Joe"}, // match on substrings
-		"My name is Joe and what is yours?": nil,     // respect end of input anchor
-		"":                                  nil,     // should not match but also not panic
-	}
-
-	for input, matches := range cases {
-		b.EmitSync(joe.ReceiveMessageEvent{Text: input})
-
-		if matches == nil {
-			select {
-			case msg := <-handledMessages:
-				t.Errorf("message handler should not have been called with %q", msg.Text)
-				continue
-			default:
-				// no joe.Message as expected, lets move on
-				continue
-			}
-		}
-
-		// Check joe.Message was handled as expected
-		select {
-		case msg := <-handledMessages:
-			assert.Equal(t, matches, msg.Matches)
-		case <-time.After(time.Second):
-			t.Errorf("timeout: %s", input)
-		}
-	}
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "Hello"})
+	assert.True(t, firstHandlerExecuted, "first handler should have been executed")
+	assert.True(t, secondHandlerExecuted, "second handler should have been executed")
 }
 
-func TestBot_RespondRegex_Empty(t *testing.T) {
+func TestBot_RespondInChannels(t *testing.T) {
 	b := joetest.NewBot(t)
-	b.RespondRegex("", func(msg joe.Message) error {
-		t.Error("should never match")
+
+	var restrictedHandlerExecuted, defaultHandlerExecuted bool
+	b.RespondInChannels([]string{"foo", "bar"}, "hello", func(msg joe.Message) error {
+		restrictedHandlerExecuted = true
+		return nil
+	})
+
+	b.Respond(".*", func(msg joe.Message) error {
+		defaultHandlerExecuted = true
 		return nil
 	})
 
 	b.Start()
 	defer b.Stop()
 
-	cases := []string{
-		"",
-		"   ",
-		"\n",
-		"\t",
-		"foobar",
-		"foo bar",
+	cases := map[string]bool{ // maps channel to whether the restricted handler should run
+		"foo": true,
+		"bar": true,
+		"baz": false,
+		"":    false,
 	}
 
-	for _, input := range cases {
-		b.EmitSync(joe.ReceiveMessageEvent{Text: input})
+	for channel, allowed := range cases {
+		restrictedHandlerExecuted, defaultHandlerExecuted = false, false // reset
+		b.EmitSync(joe.ReceiveMessageEvent{Text: "hello", Channel: channel})
+		assert.Equal(t, allowed, restrictedHandlerExecuted, "channel %q", channel)
+		assert.Equal(t, !allowed, defaultHandlerExecuted, "channel %q", channel)
 	}
 }
 
-func TestBot_RespondRegex_Invalid(t *testing.T) {
+func TestBot_RespondExceptChannels(t *testing.T) {
 	b := joetest.NewBot(t)
-	b.RespondRegex("this is not a [valid regular expression", func(msg joe.Message) error {
-		t.Error("should never match")
+
+	var restrictedHandlerExecuted, defaultHandlerExecuted bool
+	b.RespondExceptChannels([]string{"foo"}, "hello", func(msg joe.Message) error {
+		restrictedHandlerExecuted = true
 		return nil
 	})
 
-	err := b.Run()
-	require.Error(t, err)
-	require.Regexp(t, `invalid event handlers: .+\.go:\d+: error parsing regexp: missing closing \]`, err.Error())
+	b.Respond(".*", func(msg joe.Message) error {
+		defaultHandlerExecuted = true
+		return nil
+	})
+
+	b.Start()
+	defer b.Stop()
+
+	cases := map[string]bool{ // maps channel to whether the restricted handler should run
+		"foo": false,
+		"bar": true,
+		"":    true,
+	}
+
+	for channel, allowed := range cases {
+		restrictedHandlerExecuted, defaultHandlerExecuted = false, false // reset
+		b.EmitSync(joe.ReceiveMessageEvent{Text: "hello", Channel: channel})
+		assert.Equal(t, allowed, restrictedHandlerExecuted, "channel %q", channel)
+		assert.Equal(t, !allowed, defaultHandlerExecuted, "channel %q", channel)
+	}
 }
 
-func TestBot_Auth(t *testing.T) {
-	b := joetest.NewBot(t)
-	b.Respond("auth test", func(msg joe.Message) error {
-		err := b.Auth.CheckPermission("test.foo", msg.AuthorID)
-		if err != nil {
-			return msg.RespondE("I'm sorry Dave, I'm afraid I can't do that")
-		}
+func TestBot_Respond_SelfMessages(t *testing.T) {
+	cases := map[string]struct {
+		modules  []joe.Module
+		authorID string
+		handled  bool
+	}{
+		"other_user":       {authorID: "alice", handled: true},
+		"no_author":        {authorID: "", handled: true},
+		"self":             {authorID: "joe", handled: false},
+		"self_allowed":     {authorID: "joe", handled: true, modules: []joe.Module{joe.WithSelfMessages()}},
+		"other_user_allow": {authorID: "alice", handled: true, modules: []joe.Module{joe.WithSelfMessages()}},
+	}
 
-		return msg.RespondE("OK")
-	})
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			selfAwareAdapter := joe.ModuleFunc(func(conf *joe.Config) error {
+				a := joe.NewCLIAdapter("test", conf.Logger("adapter"))
+				a.Input = ioutil.NopCloser(new(bytes.Buffer))
+				a.Output = new(bytes.Buffer)
+				conf.SetAdapter(&selfAwareTestAdapter{CLIAdapter: a, userID: "joe"})
+				return nil
+			})
+
+			b := joetest.NewBot(t, append([]joe.Module{selfAwareAdapter}, c.modules...)...)
+
+			var handled bool
+			b.Respond("hello", func(msg joe.Message) error {
+				handled = true
+				return nil
+			})
+
+			b.Start()
+			defer b.Stop()
+
+			b.EmitSync(joe.ReceiveMessageEvent{Text: "hello", AuthorID: c.authorID})
+			assert.Equal(t, c.handled, handled)
+		})
+	}
+}
 
-	b.Start()
-	assert.Equal(t, "test > ", b.ReadOutput())
+func TestBot_Respond_IgnoreBots(t *testing.T) {
+	cases := map[string]struct {
+		modules []joe.Module
+		isBot   bool
+		handled bool
+	}{
+		"user":            {isBot: false, handled: true},
+		"bot":             {isBot: true, handled: true},
+		"ignore_bots":     {isBot: true, handled: false, modules: []joe.Module{joe.WithIgnoreBots()}},
+		"ignore_bots_usr": {isBot: false, handled: true, modules: []joe.Module{joe.WithIgnoreBots()}},
+	}
 
-	userID := "42"
-	b.EmitSync(joe.ReceiveMessageEvent{Text: "auth test", AuthorID: userID})
-	assert.Equal(t, "I'm sorry Dave, I'm afraid I can't do that\n", b.ReadOutput())
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			b := joetest.NewBot(t, c.modules...)
 
-	ok, err := b.Auth.Grant("test", userID)
-	require.NoError(t, err)
-	assert.True(t, ok)
+			var handled bool
+			b.Respond("hello", func(msg joe.Message) error {
+				handled = true
+				assert.Equal(t, c.isBot, msg.AuthorIsBot)
+				return nil
+			})
 
-	b.EmitSync(joe.ReceiveMessageEvent{Text: "auth test", AuthorID: userID})
-	assert.Equal(t, "OK\n", b.ReadOutput())
+			b.Start()
+			defer b.Stop()
 
-	b.Stop()
+			b.EmitSync(joe.ReceiveMessageEvent{Text: "hello", AuthorID: "alice", AuthorIsBot: c.isBot})
+			assert.Equal(t, c.handled, handled)
+		})
+	}
 }
 
-func TestBot_CloseAdapter(t *testing.T) {
-	input := &testCloser{Reader: new(bytes.Buffer)}
-	output := new(bytes.Buffer)
-	testAdapter := joe.ModuleFunc(func(conf *joe.Config) error {
-		a := joe.NewCLIAdapter("test", conf.Logger("adapter"))
-		a.Input = input
-		a.Output = output
-		conf.SetAdapter(a)
+func TestBot_RespondEvent(t *testing.T) {
+	b := joetest.NewBot(t)
+
+	type result struct {
+		evt     joe.ReceiveMessageEvent
+		matches []string
+	}
+
+	results := make(chan result, 1)
+	b.RespondEvent("remember (.+) is (.+)", func(ctx context.Context, evt joe.ReceiveMessageEvent, matches []string) error {
+		assert.NotNil(t, ctx)
+		results <- result{evt: evt, matches: matches}
 		return nil
 	})
 
-	b := joetest.NewBot(t, testAdapter)
-
 	b.Start()
-	b.Stop()
+	defer b.Stop()
 
-	assert.True(t, input.Closed)
+	evt := joe.ReceiveMessageEvent{
+		ID:       "123",
+		Text:     "Remember foo is bar",
+		AuthorID: "alice",
+		Channel:  "test",
+		Data:     "raw data",
+	}
+
+	b.EmitSync(evt)
+	select {
+	case res := <-results:
+		assert.Equal(t, evt, res.evt)
+		assert.Equal(t, []string{"foo", "bar"}, res.matches)
+	case <-time.After(time.Second):
+		t.Error("timeout")
+	}
 }
 
-func TestBot_ModuleErrors(t *testing.T) {
-	modA := joe.ModuleFunc(func(conf *joe.Config) error {
-		return errors.New("error in module A")
+func TestBot_Respond_Await(t *testing.T) {
+	prompts := make(chan string, 1)
+	notifyingAdapter := joe.ModuleFunc(func(conf *joe.Config) error {
+		conf.SetAdapter(&sendNotifyingAdapter{Adapter: conf.Adapter(), sent: prompts})
+		return nil
 	})
 
-	modB := joe.ModuleFunc(func(conf *joe.Config) error {
-		return errors.New("error in module B")
+	b := joetest.NewBot(t, notifyingAdapter)
+
+	replies := make(chan string, 1)
+	b.Respond("deploy", func(msg joe.Message) error {
+		reply, err := msg.Await("Which environment?")
+		if err != nil {
+			return err
+		}
+
+		replies <- reply
+		return nil
 	})
 
-	b := joetest.NewBot(t, modA, modB)
+	var otherMessages int
+	b.Respond("prod", func(msg joe.Message) error {
+		otherMessages++
+		return nil
+	})
 
-	err := b.Run()
-	assert.EqualError(t, err, "failed to initialize bot: error in module A; error in module B")
-}
+	b.Start()
+	defer b.Stop()
 
-func TestBot_RegistrationErrors(t *testing.T) {
-	b := joetest.NewBot(t)
+	b.Brain.Emit(joe.ReceiveMessageEvent{Text: "deploy", Channel: "test", AuthorID: "alice"})
 
-	b.Brain.RegisterHandler(42)        // not a valid handler
-	b.Brain.RegisterHandler(func() {}) // not a valid handler
+	// Wait until the bot prompted for the environment before replying.
+	select {
+	case prompt := <-prompts:
+		assert.Equal(t, "Which environment?", prompt)
+	case <-time.After(time.Second):
+		t.Fatal("Timeout")
+	}
 
-	err := b.Run()
-	require.Error(t, err)
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "prod", Channel: "test", AuthorID: "alice"})
+
+	select {
+	case reply := <-replies:
+		assert.Equal(t, "prod", reply)
+	case <-time.After(time.Second):
+		t.Error("Timeout")
+	}
+
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "prod", Channel: "test", AuthorID: "alice"})
+	assert.Equal(t, 1, otherMessages, "only messages after the conversation should reach other handlers")
+}
+
+func TestBot_Respond_AwaitShutdown(t *testing.T) {
+	b := joetest.NewBot(t, joe.WithHandlerTimeout(0))
+
+	errs := make(chan error, 1)
+	b.Respond("deploy", func(msg joe.Message) error {
+		_, err := msg.Await("Which environment?")
+		errs <- err
+		return err
+	})
+
+	b.Start()
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "deploy", Channel: "test", AuthorID: "alice"})
+	b.Stop()
+
+	select {
+	case err := <-errs:
+		assert.True(t, errors.Is(err, context.Canceled), "unexpected error: %v", err)
+	case <-time.After(time.Second):
+		t.Error("Await should return when the bot shuts down")
+	}
+}
+
+func TestBot_Respond_AwaitConcurrent(t *testing.T) {
+	b := joetest.NewBot(t)
+
+	replies := make(chan string, 2)
+	b.Respond("deploy", func(msg joe.Message) error {
+		reply, err := msg.Await("Which environment?")
+		if err != nil {
+			return err
+		}
+
+		replies <- msg.Channel + ": " + reply
+		return nil
+	})
+
+	var pings int32
+	b.Respond("ping", func(msg joe.Message) error {
+		atomic.AddInt32(&pings, 1)
+		return nil
+	})
+
+	b.Start()
+	defer b.Stop()
+
+	// EmitSync fails the test if the first conversation blocks the bot.
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "deploy", Channel: "general", AuthorID: "alice"})
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "deploy", Channel: "random", AuthorID: "bob"})
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "ping", Channel: "general", AuthorID: "carol"})
+	assert.EqualValues(t, 1, atomic.LoadInt32(&pings), "other users should not be blocked by the conversations")
+
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "staging", Channel: "random", AuthorID: "bob"})
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "prod", Channel: "general", AuthorID: "alice"})
+
+	// Both conversations continue concurrently so the order is not defined.
+	var actual []string
+	for i := 0; i < 2; i++ {
+		select {
+		case reply := <-replies:
+			actual = append(actual, reply)
+		case <-time.After(time.Second):
+			t.Fatal("Timeout")
+		}
+	}
+
+	assert.ElementsMatch(t, []string{"random: staging", "general: prod"}, actual)
+}
+
+func TestBot_Respond_AwaitTimeout(t *testing.T) {
+	clock := joetest.NewClock(time.Now())
+	b := joetest.NewBot(t, joe.WithClock(clock))
+
+	errs := make(chan error, 1)
+	b.Respond("deploy", func(msg joe.Message) error {
+		_, err := msg.Await("Which environment?")
+		errs <- err
+		return nil
+	})
+
+	b.Start()
+	defer b.Stop()
+
+	b.Brain.Emit(joe.ReceiveMessageEvent{Text: "deploy", Channel: "test", AuthorID: "alice"})
+
+	for clock.Timers() == 0 {
+		time.Sleep(time.Millisecond) // wait until the handler is waiting for a reply
+	}
+	clock.Advance(joe.DefaultAwaitTimeout)
+
+	select {
+	case err := <-errs:
+		assert.EqualError(t, err, "no reply received: context deadline exceeded")
+	case <-time.After(time.Second):
+		t.Error("Timeout")
+	}
+}
+
+func TestBot_RespondRegex(t *testing.T) {
+	b := joetest.NewBot(t)
+	handledMessages := make(chan joe.Message, 1)
+	b.RespondRegex(`name is ([^\s]+)$`, func(msg joe.Message) error {
+		t.Logf("Received joe.Message %q", msg.Text)
+		handledMessages <- msg
+		return nil
+	})
+
+	b.Start()
+	defer b.Stop()
+
+	cases := map[string][]string{ // maps input to expected matches
+		"name is Joe":                       {"Joe"}, // simple case
+		"NAME IS Joe":                       {"Joe"}, // simple case, case insensitive
+		"Hello, my name is Joe":             {"Joe"}, // match on substrings
+		"My name is Joe and what is yours?": nil,     // respect end of input anchor
+		"":                                  nil,     // should not match but also not panic
+	}
+
+	for input, matches := range cases {
+		b.EmitSync(joe.ReceiveMessageEvent{Text: input})
+
+		if matches == nil {
+			select {
+			case msg := <-handledMessages:
+				t.Errorf("message handler should not have been called with %q", msg.Text)
+				continue
+			default:
+				// no joe.Message as expected, lets move on
+				continue
+			}
+		}
+
+		// Check joe.Message was handled as expected
+		select {
+		case msg := <-handledMessages:
+			assert.Equal(t, matches, msg.Matches)
+		case <-time.After(time.Second):
+			t.Errorf("timeout: %s", input)
+		}
+	}
+}
+
+func TestBot_RespondRegex_Empty(t *testing.T) {
+	b := joetest.NewBot(t)
+	b.RespondRegex("", func(msg joe.Message) error {
+		t.Error("should never match")
+		return nil
+	})
+
+	err := b.Run()
+	require.Error(t, err)
+	require.Regexp(t, `invalid event handlers: .+\.go:\d+: message pattern must not be empty`, err.Error())
+}
+
+func TestBot_RespondRegex_Invalid(t *testing.T) {
+	b := joetest.NewBot(t)
+	b.RespondRegex("this is not a [valid regular expression", func(msg joe.Message) error {
+		t.Error("should never match")
+		return nil
+	})
+
+	err := b.Run()
+	require.Error(t, err)
+	require.Regexp(t, `invalid event handlers: .+\.go:\d+: error parsing regexp: missing closing \]`, err.Error())
+}
+
+func TestBot_RespondRegex_SamePattern(t *testing.T) {
+	b := joetest.NewBot(t, joe.WithMultiMatch())
+
+	// The compiled pattern is cached and shared between both handlers.
+	var handled []string
+	for _, name := range []string{"first", "second"} {
+		name := name
+		b.Respond("ping", func(msg joe.Message) error {
+			handled = append(handled, name)
+			return nil
+		})
+	}
+
+	b.Start()
+	defer b.Stop()
+
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "PING"})
+	assert.Equal(t, []string{"first", "second"}, handled)
+}
+
+func BenchmarkBot_Respond(b *testing.B) {
+	bot := joe.New("bench", joe.WithLogger(zap.NewNop()), joe.WithContext(context.Background()))
+	fun := func(joe.Message) error { return nil }
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bot.Respond(`remember (.+) is (.+)`, fun)
+	}
+}
+
+func TestBot_Commands(t *testing.T) {
+	b := joetest.NewBot(t)
+	assert.Empty(t, b.Commands())
+
+	noop := func(joe.Message) error { return nil }
+	b.Respond("ping", noop)
+	b.RespondRegex(`remember (.+) is (.+)`, noop)
+	b.RespondInChannels([]string{"general"}, "deploy (.+)", noop)
+	b.RespondRegex("", noop)          // invalid
+	b.RespondRegex("invalid [", noop) // invalid
+
+	assert.Equal(t, []joe.CommandInfo{
+		{Pattern: "^ping$"},
+		{Pattern: "remember (.+) is (.+)"},
+		{Pattern: "^deploy (.+)$"},
+	}, b.Commands())
+}
+
+func TestBot_RespondAliases(t *testing.T) {
+	b := joetest.NewBot(t)
+
+	var patterns, targets []string
+	b.RespondAliases([]string{"deploy (.+)", "ship (.+)", "release"}, func(msg joe.Message) error {
+		patterns = append(patterns, msg.Pattern)
+		targets = append(targets, strings.Join(msg.Matches, ","))
+		return nil
+	})
+
+	b.Start()
+	defer b.Stop()
+
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "deploy prod"})
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "ship staging"})
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "release"})
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "rollback"})
+
+	assert.Equal(t, []string{"^deploy (.+)$", "^ship (.+)$", "^release$"}, patterns)
+	assert.Equal(t, []string{"prod", "staging", ""}, targets)
+	assert.Equal(t, []joe.CommandInfo{
+		{Pattern: "^deploy (.+)$", Aliases: []string{"^ship (.+)$", "^release$"}},
+	}, b.Commands())
+}
+
+func TestBot_RespondAliases_Invalid(t *testing.T) {
+	b := joetest.NewBot(t)
+	noop := func(joe.Message) error { return nil }
+	b.RespondAliases(nil, noop)
+	b.RespondAliases([]string{"ok", "invalid ["}, noop)
+	assert.Empty(t, b.Commands())
+
+	err := b.Run()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "command aliases need at least one message pattern")
+	assert.Contains(t, err.Error(), "error parsing regexp")
+}
+
+func TestBot_RespondAliases_InvalidNotRegistered(t *testing.T) {
+	b := joetest.NewBot(t)
+
+	var called bool
+	b.RespondAliases([]string{"ok", "invalid ["}, func(joe.Message) error {
+		called = true
+		return nil
+	})
+
+	// The bot cannot be started so we run the Brain directly to check that
+	// the valid alias was not registered either.
+	go b.Brain.HandleEvents()
+	defer b.Brain.Shutdown(context.Background())
+
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "ok"})
+	assert.False(t, called)
+}
+
+func TestCommandInfo_Name(t *testing.T) {
+	cases := map[string]string{
+		"^ping$":                "ping",
+		"^Deploy (.+)$":         "deploy",
+		"^(?i)status$":          "status",
+		"remember (.+) is (.+)": "remember",
+		"(.+) is great":         "",
+		"^ (.+)":                "",
+		"invalid [":             "",
+	}
+
+	for pattern, expected := range cases {
+		cmd := joe.CommandInfo{Pattern: pattern}
+		assert.Equal(t, expected, cmd.Name(), pattern)
+	}
+}
+
+func TestBot_SyncCommands(t *testing.T) {
+	a := &commandSyncingAdapter{CLIAdapter: joe.NewCLIAdapter("test", zap.NewNop())}
+	b := joetest.NewBot(t)
+	b.Adapter = a
+
+	noop := func(joe.Message) error { return nil }
+	b.Respond("ping", noop)
+	b.Respond("deploy (.+)", noop)
+
+	b.Start()
+	b.Stop()
+
+	assert.Equal(t, []joe.CommandInfo{
+		{Pattern: "^ping$"},
+		{Pattern: "^deploy (.+)$"},
+	}, a.synced)
+	assert.True(t, b.AdapterSupports(joe.CapabilityCommands))
+}
+
+func TestBot_SyncCommands_Error(t *testing.T) {
+	a := &commandSyncingAdapter{
+		CLIAdapter: joe.NewCLIAdapter("test", zap.NewNop()),
+		err:        errors.New("invalid command name"),
+	}
+
+	b := joetest.NewBot(t)
+	b.Adapter = a
+
+	err := b.Run()
+	assert.EqualError(t, err, "failed to sync commands: invalid command name")
+}
+
+func TestBot_Auth(t *testing.T) {
+	b := joetest.NewBot(t)
+	b.Respond("auth test", func(msg joe.Message) error {
+		err := b.Auth.CheckPermission("test.foo", msg.AuthorID)
+		if err != nil {
+			return msg.RespondE("I'm sorry Dave, I'm afraid I can't do that")
+		}
+
+		return msg.RespondE("OK")
+	})
+
+	b.Start()
+	assert.Equal(t, "test > ", b.ReadOutput())
+
+	userID := "42"
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "auth test", AuthorID: userID})
+	assert.Equal(t, "I'm sorry Dave, I'm afraid I can't do that\n", b.ReadOutput())
+
+	ok, err := b.Auth.Grant("test", userID)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "auth test", AuthorID: userID})
+	assert.Equal(t, "OK\n", b.ReadOutput())
+
+	b.Stop()
+}
+
+func TestBot_CloseAdapter(t *testing.T) {
+	input := &testCloser{Reader: new(bytes.Buffer)}
+	output := new(bytes.Buffer)
+	testAdapter := joe.ModuleFunc(func(conf *joe.Config) error {
+		a := joe.NewCLIAdapter("test", conf.Logger("adapter"))
+		a.Input = input
+		a.Output = output
+		conf.SetAdapter(a)
+		return nil
+	})
+
+	b := joetest.NewBot(t, testAdapter)
+
+	b.Start()
+	b.Stop()
+
+	assert.True(t, input.Closed)
+}
+
+func TestBot_WrapAdapter(t *testing.T) {
+	var wrapped joe.Adapter
+	wrapper := joe.ModuleFunc(func(conf *joe.Config) error {
+		wrapped = conf.Adapter()
+		conf.SetAdapter(&prefixAdapter{Adapter: wrapped, prefix: "> "})
+		return nil
+	})
+
+	b := joetest.NewBot(t, wrapper)
+	require.NotNil(t, wrapped)
+
+	b.Say("test", "Hello world")
+	assert.Equal(t, "> Hello world\n", b.ReadOutput())
+}
+
+func TestBot_AdapterSupports(t *testing.T) {
+	b := joetest.NewBot(t)
+	assert.True(t, b.AdapterSupports(joe.CapabilityReactions))
+	assert.False(t, b.AdapterSupports(joe.CapabilityEphemeral))
+	assert.False(t, b.AdapterSupports(joe.CapabilitySelfAware))
+	assert.False(t, b.AdapterSupports("unknown"))
+	assert.Equal(t, []joe.Capability{joe.CapabilityReactions}, b.AdapterCapabilities())
+
+	b.Adapter = &selfAwareTestAdapter{CLIAdapter: joe.NewCLIAdapter("test", zap.NewNop())}
+	assert.True(t, b.AdapterSupports(joe.CapabilitySelfAware))
+	assert.Equal(t, []joe.Capability{joe.CapabilityReactions, joe.CapabilitySelfAware}, b.AdapterCapabilities())
+}
+
+func TestBot_AdapterSupports_WrappedAdapter(t *testing.T) {
+	// The adapter of WithSendRetry(…) implements all optional interfaces but
+	// should only report the capabilities of the adapter it wraps.
+	b := joetest.NewBot(t, joe.WithSendRetry(3, time.Millisecond))
+	assert.True(t, b.AdapterSupports(joe.CapabilityReactions))
+	assert.False(t, b.AdapterSupports(joe.CapabilityEphemeral))
+	assert.False(t, b.AdapterSupports(joe.CapabilitySelfAware))
+	assert.Equal(t, []joe.Capability{joe.CapabilityReactions}, b.AdapterCapabilities())
+}
+
+func TestUnwrapAdapter(t *testing.T) {
+	b := joetest.NewBot(t,
+		joe.WithSendRetry(3, time.Millisecond),
+		joe.WithObserver(new(testObserver)),
+		joe.WithLoopGuard(time.Minute),
+	)
+
+	_, ok := b.Adapter.(*joe.CLIAdapter)
+	assert.False(t, ok)
+	_, ok = joe.UnwrapAdapter(b.Adapter).(*joe.CLIAdapter)
+	assert.True(t, ok, "the original adapter should be accessible through all wrappers")
+
+	a := joe.NewCLIAdapter("test", zap.NewNop())
+	assert.Equal(t, a, joe.UnwrapAdapter(a))
+}
+
+func TestBot_ModuleErrors(t *testing.T) {
+	modA := joe.ModuleFunc(func(conf *joe.Config) error {
+		return errors.New("error in module A")
+	})
+
+	modB := joe.ModuleFunc(func(conf *joe.Config) error {
+		return errors.New("error in module B")
+	})
+
+	b := joetest.NewBot(t, modA, modB)
+
+	err := b.Run()
+	assert.EqualError(t, err, "failed to initialize bot: error in module A; error in module B")
+}
+
+func TestNewE(t *testing.T) {
+	modA := joe.ModuleFunc(func(conf *joe.Config) error {
+		return errors.New("error in module A")
+	})
+
+	b, err := joe.NewE("test", joe.WithContext(context.Background()), modA, joe.WithEventQueueLimit(-1))
+	assert.EqualError(t, err, "failed to initialize bot: error in module A; event queue limit must not be negative")
+	assert.Nil(t, b)
+
+	b, err = joe.NewE("test", joe.WithContext(context.Background()), joe.WithLogger(zap.NewNop()))
+	require.NoError(t, err)
+	assert.Equal(t, "test", b.Name)
+}
+
+func TestNewE_Cleanup(t *testing.T) {
+	a := joetest.NewAdapter()
+	invalid := joe.ModuleFunc(func(conf *joe.Config) error {
+		return errors.New("invalid configuration")
+	})
+
+	_, err := joe.NewE("test", joe.WithLogger(zap.NewNop()), a, invalid)
+	require.Error(t, err)
+	assert.True(t, a.Closed(), "the adapter should be closed if the bot cannot be created")
+}
+
+func TestBot_ConfigValidation(t *testing.T) {
+	invalid := joe.ModuleFunc(func(conf *joe.Config) error {
+		conf.Name = ""
+		conf.HandlerTimeout = -time.Second
+		conf.EventQueueLimit = -1
+		conf.SetAdapter(nil)
+		return nil
+	})
+
+	b := joetest.NewBot(t, invalid)
+
+	err := b.Run()
+	assert.EqualError(t, err, "failed to initialize bot: bot name must not be empty; "+
+		"bot has no adapter; handler timeout must not be negative; "+
+		"event queue limit must not be negative")
+}
+
+func TestBot_ModuleValidator(t *testing.T) {
+	b := joetest.NewBot(t, validatingModule{err: errors.New("module is misconfigured")})
+
+	err := b.Run()
+	assert.EqualError(t, err, "failed to initialize bot: module is misconfigured")
+}
+
+func TestBot_RegistrationErrors(t *testing.T) {
+	b := joetest.NewBot(t)
+
+	b.Brain.RegisterHandler(42)        // not a valid handler
+	b.Brain.RegisterHandler(func() {}) // not a valid handler
+
+	err := b.Run()
+	require.Error(t, err)
 	t.Log(err.Error())
 	assert.Regexp(t, "invalid event handlers: .+", err.Error())
 	assert.Regexp(t, "event handler is no function", err.Error())
@@ -411,6 +1188,228 @@ func TestBot_Say_Error(t *testing.T) {
 	a.AssertExpectations(t)
 }
 
+func TestBot_Broadcast(t *testing.T) {
+	a := new(MockAdapter)
+	b := joetest.NewBot(t, joe.WithDefaultChannel("general"))
+	b.Adapter = a
+
+	a.On("Send", "Hello world: the answer is 42", "general").Return(nil)
+	err := b.Broadcast("Hello %s: the answer is %d", "world", 42)
+	assert.NoError(t, err)
+
+	adapterErr := errors.New("watch your language")
+	a.On("Send", "damn it", "general").Return(adapterErr)
+	err = b.Broadcast("damn it")
+	assert.Equal(t, adapterErr, err)
+
+	a.AssertExpectations(t)
+}
+
+func TestBot_Broadcast_NoDefaultChannel(t *testing.T) {
+	b := joetest.NewBot(t)
+	err := b.Broadcast("Hello world")
+	assert.Equal(t, joe.ErrNoDefaultChannel, err)
+}
+
+func TestBot_React(t *testing.T) {
+	a := &reactByIDAdapter{CLIAdapter: joe.NewCLIAdapter("test", zap.NewNop())}
+	b := joetest.NewBot(t)
+	b.Adapter = a
+
+	err := b.React("general", "42", reactions.Thumbsup)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"general/42: thumbsup"}, a.reactions)
+	assert.True(t, b.AdapterSupports(joe.CapabilityReactByID))
+}
+
+func TestBot_React_NotImplemented(t *testing.T) {
+	b := joetest.NewBot(t)
+	err := b.React("general", "42", reactions.Thumbsup)
+	assert.Equal(t, joe.ErrNotImplemented, err)
+}
+
+func TestBot_UserPresence(t *testing.T) {
+	a := &presenceAdapter{CLIAdapter: joe.NewCLIAdapter("test", zap.NewNop())}
+	b := joetest.NewBot(t)
+	b.Adapter = a
+
+	presence, err := b.UserPresence("alice")
+	assert.NoError(t, err)
+	assert.Equal(t, joe.PresenceAway, presence)
+
+	_, err = b.UserPresence("bob")
+	assert.EqualError(t, err, "unknown user")
+	assert.True(t, b.AdapterSupports(joe.CapabilityPresence))
+}
+
+func TestBot_UserPresence_NotImplemented(t *testing.T) {
+	b := joetest.NewBot(t)
+	_, err := b.UserPresence("alice")
+	assert.Equal(t, joe.ErrNotImplemented, err)
+}
+
+func TestWithCommandErrorMessage(t *testing.T) {
+	cases := map[string]struct {
+		modules  []joe.Module
+		handler  func(joe.Message) error
+		expected string
+	}{
+		"error": {
+			modules:  []joe.Module{joe.WithCommandErrorMessage("Sorry, something went wrong")},
+			handler:  func(joe.Message) error { return errors.New("database is down") },
+			expected: "Sorry, something went wrong\n",
+		},
+		"panic": {
+			modules:  []joe.Module{joe.WithCommandErrorMessage("Sorry, something went wrong")},
+			handler:  func(joe.Message) error { panic("oops") },
+			expected: "Sorry, something went wrong\n",
+		},
+		"success": {
+			modules:  []joe.Module{joe.WithCommandErrorMessage("Sorry, something went wrong")},
+			handler:  func(joe.Message) error { return nil },
+			expected: "",
+		},
+		"disabled": {
+			handler:  func(joe.Message) error { return errors.New("database is down") },
+			expected: "",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			b := joetest.NewBot(t, c.modules...)
+			b.Respond("deploy", c.handler)
+
+			b.Start()
+			defer b.Stop()
+
+			b.ReadOutput() // discard any previous output
+			b.EmitSync(joe.ReceiveMessageEvent{Text: "deploy", Channel: "general"})
+			assert.Equal(t, c.expected, b.ReadOutput())
+		})
+	}
+}
+
+func TestWithCommandErrorResponder(t *testing.T) {
+	b := joetest.NewBot(t, joe.WithCommandErrorResponder(func(msg joe.Message, err error) string {
+		if msg.Matches[0] == "staging" {
+			return "" // stay silent
+		}
+
+		return fmt.Sprintf("Sorry %s, failed to deploy %s: %v", msg.AuthorID, msg.Matches[0], err)
+	}))
+
+	b.Respond("deploy (.+)", func(msg joe.Message) error {
+		return errors.New("database is down")
+	})
+
+	b.Start()
+	defer b.Stop()
+
+	b.ReadOutput() // discard any previous output
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "deploy prod", AuthorID: "alice"})
+	assert.Equal(t, "Sorry alice, failed to deploy prod: database is down\n", b.ReadOutput())
+
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "deploy staging", AuthorID: "alice"})
+	assert.Equal(t, "", b.ReadOutput())
+}
+
+func TestBot_Channels(t *testing.T) {
+	a := &listingAdapter{CLIAdapter: joe.NewCLIAdapter("test", zap.NewNop())}
+	b := joetest.NewBot(t)
+	b.Adapter = a
+
+	channels, err := b.Channels()
+	assert.NoError(t, err)
+	assert.Equal(t, []joe.Channel{{ID: "C1", Name: "general"}}, channels)
+
+	members, err := b.ChannelMembers("C1")
+	assert.NoError(t, err)
+	assert.Equal(t, []joe.User{{ID: "U1", Name: "alice"}}, members)
+
+	_, err = b.ChannelMembers("C2")
+	assert.EqualError(t, err, "unknown channel")
+
+	assert.True(t, b.AdapterSupports(joe.CapabilityChannels))
+	assert.True(t, b.AdapterSupports(joe.CapabilityMembers))
+}
+
+func TestBot_Channels_NotImplemented(t *testing.T) {
+	b := joetest.NewBot(t)
+	_, err := b.Channels()
+	assert.Equal(t, joe.ErrNotImplemented, err)
+
+	_, err = b.ChannelMembers("C1")
+	assert.Equal(t, joe.ErrNotImplemented, err)
+}
+
+func TestBot_Drain(t *testing.T) {
+	type TestEvent struct{}
+
+	var sequence []string
+	a := &drainingAdapter{CLIAdapter: joe.NewCLIAdapter("test", zap.NewNop())}
+	a.drain = func(context.Context) error {
+		sequence = append(sequence, "drain")
+		return nil
+	}
+
+	b := joetest.NewBot(t)
+	b.Adapter = a
+
+	b.Brain.RegisterHandler(func(TestEvent) {
+		sequence = append(sequence, "event")
+	})
+	b.Brain.RegisterHandler(func(evt joe.ShutdownEvent) {
+		assert.Equal(t, joe.ShutdownDrain, evt.Reason)
+		sequence = append(sequence, "shutdown")
+	})
+
+	b.Start()
+	err := b.Drain(context.Background())
+	require.NoError(t, err)
+	b.Stop() // checks that Bot.Run() has returned
+
+	assert.Equal(t, []string{"drain", "shutdown"}, sequence)
+	assert.True(t, b.AdapterSupports(joe.CapabilityDrain))
+}
+
+func TestBot_Drain_PendingEvents(t *testing.T) {
+	type TestEvent struct{}
+
+	b := joetest.NewBot(t)
+
+	var handled int
+	b.Brain.RegisterHandler(func(TestEvent) {
+		handled++
+	})
+
+	b.Start()
+	for i := 0; i < 10; i++ {
+		b.Brain.Emit(TestEvent{})
+	}
+
+	err := b.Drain(context.Background())
+	require.NoError(t, err)
+	b.Stop()
+
+	assert.Equal(t, 10, handled, "all pending events should be processed")
+}
+
+func TestBot_Drain_Error(t *testing.T) {
+	a := &drainingAdapter{CLIAdapter: joe.NewCLIAdapter("test", zap.NewNop())}
+	a.drain = func(context.Context) error {
+		return errors.New("connection lost")
+	}
+
+	b := joetest.NewBot(t)
+	b.Adapter = a
+
+	b.Start()
+	err := b.Drain(context.Background())
+	assert.EqualError(t, err, "failed to drain adapter: connection lost")
+	b.Stop() // the bot should still be shut down
+}
+
 // TestBot_HandlerEvents tests if event handler functions can safely (i.e. without
 // deadlock or panic) emit new events.
 func TestBot_HandlerEvents(t *testing.T) {
@@ -431,7 +1430,6 @@ func TestBot_HandlerEvents(t *testing.T) {
 		// This test checks that emitting events from within an event handler
 		// does not deadlock the Brain.
 		for i := 0; i < testEventsPerMsg; i++ {
-			// TODO: if we use a callback here, will it then deadlock?
 			b.Brain.Emit(TestEvent{N: i})
 		}
 	})
@@ -452,6 +1450,156 @@ func TestBot_HandlerEvents(t *testing.T) {
 	}
 }
 
+// TestBot_CallbackEvents tests if event callbacks can safely (i.e. without
+// deadlock or panic) emit new events, even though they are executed by the
+// Brain while it is processing another event.
+func TestBot_CallbackEvents(t *testing.T) {
+	b := joetest.NewBot(t)
+
+	type TestEvent struct {
+		N int
+	}
+
+	var receivedEvents []TestEvent
+	b.Brain.RegisterHandler(func(evt TestEvent) {
+		receivedEvents = append(receivedEvents, evt)
+	})
+
+	n := 10
+	var emit func(i int)
+	emit = func(i int) {
+		b.Brain.Emit(TestEvent{N: i}, func(joe.Event) {
+			// Emit the next event from within the callback of this event.
+			if i+1 < n {
+				emit(i + 1)
+			}
+		})
+	}
+
+	done := make(chan bool)
+	b.Brain.RegisterHandler(func(evt TestEvent) {
+		if evt.N == n-1 {
+			done <- true
+		}
+	})
+
+	b.Start()
+	emit(0)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+
+	b.Stop()
+
+	require.Len(t, receivedEvents, n)
+	for i, evt := range receivedEvents {
+		assert.Equal(t, i, evt.N)
+	}
+}
+
+type selfAwareTestAdapter struct {
+	*joe.CLIAdapter
+	userID string
+}
+
+func (a *selfAwareTestAdapter) BotUserID() string {
+	return a.userID
+}
+
+type reactByIDAdapter struct {
+	*joe.CLIAdapter
+	reactions []string
+}
+
+func (a *reactByIDAdapter) ReactID(r reactions.Reaction, channel, messageID string) error {
+	a.reactions = append(a.reactions, channel+"/"+messageID+": "+r.Shortcode)
+	return nil
+}
+
+type presenceAdapter struct {
+	*joe.CLIAdapter
+}
+
+func (a *presenceAdapter) Presence(userID string) (joe.Presence, error) {
+	if userID != "alice" {
+		return "", errors.New("unknown user")
+	}
+
+	return joe.PresenceAway, nil
+}
+
+type listingAdapter struct {
+	*joe.CLIAdapter
+}
+
+func (a *listingAdapter) Channels() ([]joe.Channel, error) {
+	return []joe.Channel{{ID: "C1", Name: "general"}}, nil
+}
+
+func (a *listingAdapter) Members(channel string) ([]joe.User, error) {
+	if channel != "C1" {
+		return nil, errors.New("unknown channel")
+	}
+
+	return []joe.User{{ID: "U1", Name: "alice"}}, nil
+}
+
+type drainingAdapter struct {
+	*joe.CLIAdapter
+	drain func(context.Context) error
+}
+
+func (a *drainingAdapter) Drain(ctx context.Context) error {
+	return a.drain(ctx)
+}
+
+type commandSyncingAdapter struct {
+	*joe.CLIAdapter
+	synced []joe.CommandInfo
+	err    error
+}
+
+func (a *commandSyncingAdapter) SyncCommands(commands []joe.CommandInfo) error {
+	a.synced = commands
+	return a.err
+}
+
+type sendNotifyingAdapter struct {
+	joe.Adapter
+	sent chan string
+}
+
+func (a *sendNotifyingAdapter) Send(text, channel string) error {
+	err := a.Adapter.Send(text, channel)
+	a.sent <- text
+	return err
+}
+
+type validatingModule struct {
+	err error
+}
+
+func (validatingModule) Apply(*joe.Config) error {
+	return nil
+}
+
+func (m validatingModule) Validate(*joe.Config) error {
+	return m.err
+}
+
+// prefixAdapter is an example of a module that wraps the configured Adapter.
+type prefixAdapter struct {
+	joe.Adapter
+	prefix string
+}
+
+func (a *prefixAdapter) Send(text, channel string) error {
+	return a.Adapter.Send(a.prefix+text, channel)
+}
+
 type testCloser struct {
 	Closed bool
 	io.Reader
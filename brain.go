@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"reflect"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -22,28 +23,64 @@ type Brain struct {
 	eventsInput chan Event // input for any new events, the Brain ensures that callers never block when writing to it
 	eventsLoop  chan Event // used in Brain.HandleEvents() to actually process the events
 	shutdown    chan shutdownRequest
+	closing     chan struct{} // closed as soon as the Brain starts to shut down
+
+	// inputMu guarantees that no event is sent on eventsInput after it was
+	// closed. Emitters hold a read lock while they send an event.
+	inputMu sync.RWMutex
 
 	mu             sync.RWMutex // mu protects concurrent access to the handlers
-	handlers       map[reflect.Type][]eventHandler
-	handlerTimeout time.Duration // zero means no timeout, defaults to one minute
+	handlers       map[reflect.Type][]registeredHandler
+	interfaces     []reflect.Type     // all interface types of the handlers, checked for each event
+	handlerSeq     int                // incremented for each registered handler to preserve the registration order
+	handlerTimeout time.Duration      // zero means no timeout, defaults to one minute
+	clock          Clock              // used for the backoff of HandlerRetry(…), see WithClock(…)
+	observers      []Observer         // notified about all handled events, see WithObserver(…)
+	tracer         Tracer             // optional, see WithTracer(…)
+	errorPolicy    HandlerErrorPolicy // optional, see WithHandlerErrorPolicy(…)
+
+	running    runningHandlers // all handlers that have not returned yet
+	queueSlots chan struct{}   // limits the events emitted via EmitBlocking(…), nil means unlimited
+
+	// intercept is optional and may consume events before they are queued
+	// (e.g. replies to Message.Await(…)).
+	intercept func(event interface{}) bool
+
+	// matching caches the result of determineHandlers(…) for each event type
+	// and is reset whenever a new handler is registered. It is protected by mu.
+	matching map[reflect.Type][]registeredHandler
 
 	registrationErrs []error // any errors that occurred during setup (e.g. in Bot.RegisterHandler)
 	handlingEvents   int32   // accessed atomically (non-zero means the event handler was started)
 	closed           int32   // accessed atomically (non-zero means the brain was shutdown already)
+	adapterEvents    bool    // if true, Brain.EmitAdapterEvent(…) emits events, see WithAdapterEvents()
 }
 
 // An Event represents a concrete event type and optional callbacks that are
 // triggered when the event was processed by all registered handlers.
+//
+// If a handler called FinishEventContent(…), the callbacks receive the Event
+// with AbortEarly set to true and AbortedBy set to the name of that handler.
+// This lets callbacks distinguish events that were claimed by a handler (e.g.
+// a message handler registered via Bot.Respond(…)) from events that were
+// processed by all handlers.
 type Event struct {
 	Data       interface{}
 	Callbacks  []func(Event)
 	AbortEarly bool
+	AbortedBy  string        // name of the handler that called FinishEventContent(…)
+	Results    []interface{} // results added by the handlers via AddEventResult(…)
+
+	slot bool            // true if the event occupies a slot in the queue, see Brain.EmitBlocking(…)
+	ctx  context.Context // optional context of the caller, see Brain.EmitCtx(…)
+	done []func()        // registered via OnEventDone(…)
 }
 
 // The shutdownRequest type is used when signaling shutdown information between
 // Brain.Shutdown() and the Brain.HandleEvents loop.
 type shutdownRequest struct {
 	ctx      context.Context
+	reason   ShutdownReason
 	callback chan bool
 }
 
@@ -51,6 +88,93 @@ type shutdownRequest struct {
 // of a concrete event type.
 type eventHandler func(context.Context, reflect.Value) error
 
+// A registeredHandler is an eventHandler together with the information that is
+// needed to determine the order in which handlers are executed.
+type registeredHandler struct {
+	handle   eventHandler
+	name     string // name of the handler function, passed to all observers
+	priority int
+	seq      int           // registration order
+	attempts int           // how often the handler is executed if it fails, see HandlerRetry(…)
+	backoff  time.Duration // initial delay between attempts
+}
+
+// A HandlerOption can be passed to Brain.RegisterHandler(…) to change how the
+// registered handler is executed.
+type HandlerOption func(*handlerOptions)
+
+type handlerOptions struct {
+	priority int
+	attempts int
+	backoff  time.Duration
+	err      error
+}
+
+// HandlerPriority is a HandlerOption to set the priority of an event handler.
+// Handlers with a higher priority are executed before handlers with a lower
+// priority. Handlers with the same priority are executed in the order in which
+// they have been registered. The default priority is zero.
+func HandlerPriority(priority int) HandlerOption {
+	return func(opts *handlerOptions) {
+		opts.priority = priority
+	}
+}
+
+// HandlerRetry is a HandlerOption to execute an event handler up to the given
+// number of attempts if it returns an error (e.g. because it does flaky I/O).
+// The backoff duration is doubled after each failed attempt. Only the failed
+// handler is retried and the next handler of the event is executed after the
+// last attempt. No more attempts are made if the error is a RetryableError
+// that is not retryable or if the context of the handler is done (e.g. because
+// the handler timeout is reached, which applies to all attempts together).
+//
+// Note that the Brain processes events sequentially, so all other events are
+// delayed while the handler waits for its next attempt. Therefore the backoff
+// should be short and the total backoff is limited by the handler timeout (see
+// WithHandlerTimeout(…)): no more attempts are made if the next backoff would
+// exceed it.
+func HandlerRetry(attempts int, backoff time.Duration) HandlerOption {
+	return func(opts *handlerOptions) {
+		if attempts < 1 {
+			opts.err = errors.New("handler retry attempts must be at least one")
+			return
+		}
+
+		opts.attempts = attempts
+		opts.backoff = backoff
+	}
+}
+
+// An ErrorDecision is returned by a HandlerErrorPolicy to decide how the Brain
+// continues after an event handler returned an error.
+type ErrorDecision int
+
+// The possible decisions of a HandlerErrorPolicy.
+const (
+	ContinueHandlers ErrorDecision = iota // execute the remaining handlers of the event
+	AbortHandlers                         // do not execute any more handlers for the event
+)
+
+// A HandlerErrorPolicy decides what the Brain does when an event handler
+// returns an error. The error is always logged before the policy is consulted.
+// Besides the ContinueOnError and AbortOnError policies you can also implement
+// your own policy (e.g. to abort only on specific errors via errors.Is(…)).
+type HandlerErrorPolicy func(error) ErrorDecision
+
+// ContinueOnError is the default HandlerErrorPolicy which executes the remaining
+// handlers of an event even if a handler returned an error.
+func ContinueOnError(error) ErrorDecision {
+	return ContinueHandlers
+}
+
+// AbortOnError is a HandlerErrorPolicy which stops executing the remaining
+// handlers of an event as soon as a handler returned an error. Like with
+// FinishEventContent(…), the callbacks of the event receive it with AbortEarly
+// set to true and AbortedBy set to the name of the failed handler.
+func AbortOnError(error) ErrorDecision {
+	return AbortHandlers
+}
+
 // ctxKey is used to pass meta information to event handlers via the context.
 type ctxKey string
 
@@ -58,6 +182,27 @@ type ctxKey string
 // instance in a handler.
 const ctxKeyEvent ctxKey = "event"
 
+// ctxKeyDetach is the context key under which a handler can lookup the function
+// that detaches it from the event loop, see detachHandler(…).
+const ctxKeyDetach ctxKey = "detach"
+
+// errHandlerDetached is returned by Brain.executeEventHandlerOnce(…) if the
+// handler detached itself from the event loop and is still running.
+const errHandlerDetached = Error("handler detached from event loop")
+
+// detachHandler lets the Brain continue to process other events while the
+// handler with the given context keeps running in the background (e.g. while
+// it waits for a reply via Message.Await(…)). Once detached, the Brain treats
+// the handler as if it returned successfully, so all remaining handlers and
+// callbacks of the event are executed right away. Errors that the handler
+// returns afterwards are only logged. The context of the handler stays valid
+// until it returns, its handler timeout is reached or the Brain shuts down.
+func detachHandler(ctx context.Context) {
+	if detach, ok := ctx.Value(ctxKeyDetach).(func()); ok {
+		detach()
+	}
+}
+
 // FinishEventContent can be called from within your event handler functions
 // to indicate that the Brain should not execute any other handlers after the
 // calling handler has returned.
@@ -68,6 +213,30 @@ func FinishEventContent(ctx context.Context) {
 	}
 }
 
+// AddEventResult can be called from within your event handler functions to
+// attach a result to the currently handled event. All results are returned by
+// Brain.Request(…) after all handlers have processed the event. The result must
+// be added before the handler returns.
+func AddEventResult(ctx context.Context, result interface{}) {
+	evt, _ := ctx.Value(ctxKeyEvent).(*Event)
+	if evt != nil {
+		evt.Results = append(evt.Results, result)
+	}
+}
+
+// OnEventDone can be called from within your event handler functions to
+// register a function that is executed after the currently handled event was
+// processed completely, i.e. after all handlers and callbacks of the event.
+// This can be used to release resources that are tied to the event. The
+// functions are executed in reverse order of their registration (like defer)
+// and must be registered before the handler returns.
+func OnEventDone(ctx context.Context, fun func()) {
+	evt, _ := ctx.Value(ctxKeyEvent).(*Event)
+	if evt != nil {
+		evt.done = append(evt.done, fun)
+	}
+}
+
 // NewBrain creates a new robot Brain. If the passed logger is nil it will
 // fallback to the zap.NewNop() logger.
 func NewBrain(logger *zap.Logger) *Brain {
@@ -80,8 +249,10 @@ func NewBrain(logger *zap.Logger) *Brain {
 		eventsInput:    make(chan Event),
 		eventsLoop:     make(chan Event),
 		shutdown:       make(chan shutdownRequest),
-		handlers:       make(map[reflect.Type][]eventHandler),
+		closing:        make(chan struct{}),
+		handlers:       make(map[reflect.Type][]registeredHandler),
 		handlerTimeout: time.Minute,
+		clock:          realClock{},
 	}
 
 	b.consumeEvents()
@@ -123,6 +294,12 @@ func (b *Brain) isClosed() bool {
 //   // accept a context and/or return an error like other handlers.
 //   func(context.Context, interface{}) error
 //
+//   // The handler receives the event as the interface value so there is no
+//   // need for a type assertion in the handler. For instance, the following
+//   // handler is called for all events that have a "GetChannel() string"
+//   // method.
+//   func(interface{ GetChannel() string })
+//
 // The event, that will be dispatched to the passed handler function, corresponds
 // directly to the accepted function argument. For instance if you want to emit
 // and receive a custom event you can implement it like this:
@@ -135,14 +312,21 @@ func (b *Brain) isClosed() bool {
 //     })
 //
 // If multiple handlers are registered for the same event type, then they are
-// all executed in the order in which they have been registered.
+// all executed in the order in which they have been registered. You can pass
+// the HandlerPriority(…) option to execute a handler before or after the other
+// handlers (e.g. to implement a filter that uses FinishEventContent(…)).
+//
+// Long running handlers should accept a context and return as soon as it is
+// done. A handler cannot be stopped from the outside, so if it ignores the
+// context it keeps running in the background after the handler timeout and the
+// Brain logs a warning for each such handler when it shuts down.
 //
 // You should register all handlers before you start the bot via Bot.Run(…).
 // While registering handlers later is also possible, any registration errors
 // will silently be ignored if you register an invalid handler when the bot is
 // already running.
-func (b *Brain) RegisterHandler(fun interface{}) {
-	err := b.registerHandler(fun)
+func (b *Brain) RegisterHandler(fun interface{}, opts ...HandlerOption) {
+	err := b.registerHandler(fun, opts)
 	if err != nil {
 		caller := firstExternalCaller()
 		err = fmt.Errorf("%s: %w", caller, err)
@@ -150,31 +334,64 @@ func (b *Brain) RegisterHandler(fun interface{}) {
 	}
 }
 
-func (b *Brain) registerHandler(fun interface{}) error {
-	handler := reflect.ValueOf(fun)
-	handlerType := handler.Type()
-	if handlerType.Kind() != reflect.Func {
+// ValidateHandler checks if the given function is a valid event handler that
+// can be passed to Brain.RegisterHandler(…) and returns an error if it is not.
+// Since Brain.RegisterHandler(…) only returns registration errors on the next
+// call to Bot.Run(), this function can be used to test the signatures of event
+// handlers directly (e.g. in unit tests).
+func ValidateHandler(fun interface{}) error {
+	handlerType := reflect.TypeOf(fun)
+	if handlerType == nil || handlerType.Kind() != reflect.Func {
 		return errors.New("event handler is no function")
 	}
 
-	evtType, withContext, err := checkHandlerParams(handlerType)
-	if err != nil {
+	if _, _, err := checkHandlerParams(handlerType); err != nil {
 		return err
 	}
 
-	returnsErr, err := checkHandlerReturnValues(handlerType)
-	if err != nil {
+	_, err := checkHandlerReturnValues(handlerType)
+	return err
+}
+
+func (b *Brain) registerHandler(fun interface{}, opts []HandlerOption) error {
+	if err := ValidateHandler(fun); err != nil {
 		return err
 	}
 
+	// The handler is valid so we can ignore the errors here.
+	handler := reflect.ValueOf(fun)
+	evtType, withContext, _ := checkHandlerParams(handler.Type())
+	returnsErr, _ := checkHandlerReturnValues(handler.Type())
+
 	b.logger.Debug("Registering new event handler",
 		zap.Stringer("event_type", evtType),
 	)
 
+	var options handlerOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.err != nil {
+		return options.err
+	}
+
 	handlerFun := newHandlerFunc(handler, withContext, returnsErr)
 
 	b.mu.Lock()
-	b.handlers[evtType] = append(b.handlers[evtType], handlerFun)
+	b.handlerSeq++
+	b.matching = nil // the new handler may match events of any cached type
+	if evtType.Kind() == reflect.Interface && len(b.handlers[evtType]) == 0 {
+		b.interfaces = append(b.interfaces, evtType)
+	}
+	b.handlers[evtType] = append(b.handlers[evtType], registeredHandler{
+		handle:   handlerFun,
+		name:     handlerName(handler),
+		priority: options.priority,
+		seq:      b.handlerSeq,
+		attempts: options.attempts,
+		backoff:  options.backoff,
+	})
 	b.mu.Unlock()
 
 	return nil
@@ -187,6 +404,11 @@ func (b *Brain) registerHandler(fun interface{}) error {
 // handlers. If you want to wait until all handlers have processed the event you
 // can pass one or more callback functions that will be executed when all
 // handlers finished execution of this event.
+//
+// It is safe to call Emit from within event handlers and callbacks. Note
+// however that callbacks are executed by the Brain while it is processing
+// events, so a callback must not block until another event was processed
+// (e.g. via Brain.Request(…)). Otherwise it would wait forever.
 func (b *Brain) Emit(event interface{}, callbacks ...func(Event)) {
 	if b.isClosed() {
 		b.logger.Debug(
@@ -196,7 +418,152 @@ func (b *Brain) Emit(event interface{}, callbacks ...func(Event)) {
 		return
 	}
 
-	b.eventsInput <- Event{Data: event, Callbacks: callbacks}
+	if event == nil {
+		b.logger.Error("Ignoring nil event")
+		return
+	}
+
+	_ = b.emit(Event{Data: event, Callbacks: callbacks})
+}
+
+// EmitCtx is like Brain.Emit(…) but the handlers of the event receive a context
+// that is derived from the given context instead of a new one. This way values
+// of the context (e.g. tracing information) as well as its deadline and
+// cancellation are propagated from the caller to the event handlers. This is
+// useful if the event is emitted in response to an external request, e.g. by
+// an HTTP server.
+//
+// Note that the event may still be queued after the context is done. In this
+// case the handlers receive a context that is done already. The context of the
+// handlers is also done if the Brain shuts down before the event was handled,
+// so the event cannot delay a shutdown beyond its deadline.
+func (b *Brain) EmitCtx(ctx context.Context, event interface{}, callbacks ...func(Event)) {
+	if b.isClosed() {
+		b.logger.Debug(
+			"Ignoring new event because brain is currently shutting down or is already closed",
+			zap.String("type", fmt.Sprintf("%T", event)),
+		)
+		return
+	}
+
+	if event == nil {
+		b.logger.Error("Ignoring nil event")
+		return
+	}
+
+	_ = b.emit(Event{Data: event, Callbacks: callbacks, ctx: ctx})
+}
+
+// EmitBlocking is like Brain.Emit(…) but it blocks if the bot was configured
+// with a limit on the number of queued events (see WithEventQueueLimit(…)) and
+// that limit is reached. In this case EmitBlocking waits until enough events
+// have been processed or until the context is done, in which case the context
+// error is returned. Without a limit it never blocks. If the Brain is already
+// shut down or starts to shut down while EmitBlocking waits, ErrBrainClosed is
+// returned.
+//
+// EmitBlocking is meant to be used by Adapters which may receive more events
+// than the bot can handle (e.g. a busy chat). It must not be called from within
+// an event handler because the Brain processes events sequentially and a full
+// queue would thus block forever. Event handlers should use Brain.Emit(…).
+func (b *Brain) EmitBlocking(ctx context.Context, event interface{}, callbacks ...func(Event)) error {
+	if b.isClosed() {
+		return ErrBrainClosed
+	}
+
+	if event == nil {
+		return ErrNilEvent
+	}
+
+	evt := Event{Data: event, Callbacks: callbacks}
+	if b.queueSlots != nil {
+		select {
+		case b.queueSlots <- struct{}{}:
+			evt.slot = true
+		case <-b.closing:
+			return ErrBrainClosed
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return b.emit(evt)
+}
+
+// emit queues the event or returns ErrBrainClosed if the Brain was shut down
+// in the meantime, in which case the slot of the event is released again.
+func (b *Brain) emit(evt Event) error {
+	if b.intercept != nil && b.intercept(evt.Data) {
+		b.releaseSlot(evt)
+
+		// The event was consumed so it is not queued but we still have to run
+		// the callbacks without blocking the caller, just like a queued event.
+		go func() {
+			evt.AbortEarly = true
+			for _, callback := range evt.Callbacks {
+				callback(evt)
+			}
+		}()
+		return nil
+	}
+
+	b.inputMu.RLock()
+	defer b.inputMu.RUnlock()
+
+	if b.isClosed() {
+		b.releaseSlot(evt)
+		return ErrBrainClosed
+	}
+
+	b.eventsInput <- evt
+	return nil
+}
+
+// closeInput closes the input channel of the event queue once all emitters
+// that are currently sending an event are done.
+func (b *Brain) closeInput() {
+	b.inputMu.Lock()
+	close(b.eventsInput)
+	b.inputMu.Unlock()
+}
+
+// releaseSlot frees the space in the event queue of an event that was emitted
+// via Brain.EmitBlocking(…).
+func (b *Brain) releaseSlot(evt Event) {
+	if evt.slot {
+		<-b.queueSlots
+	}
+}
+
+// Request emits the given event and blocks until all registered handlers have
+// processed it. It returns all results that have been attached to the event by
+// the handlers via AddEventResult(…). This allows using events as a lightweight
+// query mechanism where the handler which has the requested data answers it.
+//
+// If the context is done before the event was processed, the context error is
+// returned. If the Brain is already shut down, ErrBrainClosed is returned.
+// Request must not be called from within an event handler because
+// the Brain processes events sequentially and would thus block forever.
+func (b *Brain) Request(ctx context.Context, event interface{}) ([]interface{}, error) {
+	if b.isClosed() {
+		return nil, ErrBrainClosed
+	}
+
+	if event == nil {
+		return nil, ErrNilEvent
+	}
+
+	done := make(chan []interface{}, 1)
+	b.Emit(event, func(evt Event) {
+		done <- evt.Results
+	})
+
+	select {
+	case results := <-done:
+		return results, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 }
 
 // HandleEvents starts the event handling loop of the Brain.
@@ -220,12 +587,14 @@ func (b *Brain) HandleEvents() {
 				// Brain.consumeEvents() is done processing all remaining events
 				// and we can now safely shutdown the event handler, knowing that
 				// all pending events have been processed.
-				b.handleEvent(ctx, Event{Data: ShutdownEvent{}})
+				b.handleEvent(ctx, Event{Data: ShutdownEvent{Reason: shutdown.reason}})
+				b.logRunningHandlers(ctx)
 				shutdown.callback <- true
 				return
 			}
 
 			b.handleEvent(ctx, evt)
+			b.releaseSlot(evt)
 
 		case shutdown = <-b.shutdown:
 			// The Brain is shutting down. We have to close the input channel so
@@ -234,7 +603,7 @@ func (b *Brain) HandleEvents() {
 			// done it will close the events loop channel and the case above will
 			// use the shutdown callback and return from this function.
 			ctx = shutdown.ctx
-			close(b.eventsInput)
+			b.closeInput()
 			atomic.StoreInt32(&b.handlingEvents, 0)
 		}
 	}
@@ -299,6 +668,14 @@ func (b *Brain) consumeEvents() {
 // using the reflect API. When all applicable handlers are called (maybe none)
 // the function runs all event callbacks.
 func (b *Brain) handleEvent(ctx context.Context, evt Event) {
+	if evt.Data == nil {
+		// This should never happen because all functions that emit events
+		// already ignore nil events but a panic would stop the whole bot.
+		b.logger.Error("Ignoring nil event")
+		return
+	}
+
+	start := b.clock.Now()
 	event := reflect.ValueOf(evt.Data)
 	typ := event.Type()
 	handlers := b.determineHandlers(typ)
@@ -308,18 +685,40 @@ func (b *Brain) handleEvent(ctx context.Context, evt Event) {
 		zap.Int("handlers", len(handlers)),
 	)
 
+	if evt.ctx != nil {
+		var stop func()
+		ctx, stop = withLoopContext(evt.ctx, ctx)
+		defer stop()
+	}
+
 	ctx = context.WithValue(ctx, ctxKeyEvent, &evt)
 
+	endTrace := func() {}
+	if b.tracer != nil {
+		ctx, endTrace = b.tracer.StartEvent(ctx, typ.String())
+	}
+
 	for _, handler := range handlers {
-		err := b.executeEventHandler(ctx, handler, event)
+		handlerStart := b.clock.Now()
+		err := b.executeTracedEventHandler(ctx, handler, typ, event)
 		if err != nil {
 			b.logger.Error("Event handler failed",
-				// TODO: somehow log the name of the handler
+				zap.String("handler", handler.name),
 				zap.Error(err),
 			)
+
+			if b.errorPolicy != nil && b.errorPolicy(err) == AbortHandlers {
+				evt.AbortEarly = true
+			}
+		}
+
+		for _, o := range b.observers {
+			o.ObserveHandler(typ.String(), handler.name, b.clock.Now().Sub(handlerStart), err)
 		}
 
 		if evt.AbortEarly {
+			evt.AbortedBy = handler.name
+
 			// Abort handler execution early instead of running any more
 			// handlers. The event state may have been changed by a handler, e.g.
 			// using the FinishEventContent(…) function.
@@ -327,44 +726,221 @@ func (b *Brain) handleEvent(ctx context.Context, evt Event) {
 		}
 	}
 
+	endTrace()
+	for _, o := range b.observers {
+		o.ObserveEvent(typ.String(), len(handlers), b.clock.Now().Sub(start))
+	}
+
 	for _, callback := range evt.Callbacks {
 		callback(evt)
 	}
+
+	for i := len(evt.done) - 1; i >= 0; i-- {
+		evt.done[i]()
+	}
+}
+
+// withLoopContext returns a context with the values of the context that was
+// passed to Brain.EmitCtx(…) which is done as soon as either this context or
+// the context of the event loop is done. This way events that were emitted with
+// their own context still respect the deadline of a shutdown. The returned
+// function must be called once the event was handled. It does not cancel the
+// context so handlers that detached from the event loop keep running.
+func withLoopContext(evtCtx, loopCtx context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(evtCtx)
+	ctx = loopDeadlineContext{Context: ctx, loop: loopCtx}
+
+	stop := make(chan struct{})
+	go func() {
+		select {
+		case <-loopCtx.Done():
+			cancel()
+		case <-ctx.Done():
+		case <-stop:
+		}
+	}()
+
+	return ctx, func() { close(stop) }
+}
+
+// loopDeadlineContext reports the earlier deadline of its embedded context and
+// the context of the event loop, see withLoopContext(…).
+type loopDeadlineContext struct {
+	context.Context
+	loop context.Context
+}
+
+// Deadline implements context.Context.
+func (c loopDeadlineContext) Deadline() (time.Time, bool) {
+	deadline, ok := c.Context.Deadline()
+	if loopDeadline, loopOK := c.loop.Deadline(); loopOK && (!ok || loopDeadline.Before(deadline)) {
+		return loopDeadline, true
+	}
+
+	return deadline, ok
 }
 
-func (b *Brain) determineHandlers(evtType reflect.Type) []eventHandler {
+// determineHandlers returns all handlers for events of the given type in the
+// order in which they should be executed. The result is cached until the next
+// handler is registered and must not be modified by the caller.
+func (b *Brain) determineHandlers(evtType reflect.Type) []registeredHandler {
 	b.mu.RLock()
-	defer b.mu.RUnlock()
+	matching, ok := b.matching[evtType]
+	b.mu.RUnlock()
+	if ok {
+		return matching
+	}
 
-	var handlers []eventHandler
-	for handlerType, hh := range b.handlers {
-		if handlerType == evtType {
-			handlers = append(handlers, hh...)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// Copy the handlers of the exact type so we do not modify the map below.
+	matching = append([]registeredHandler(nil), b.handlers[evtType]...)
+
+	// Only the handlers of interface types need to be checked separately
+	// which are typically much fewer than all registered event types.
+	for _, iface := range b.interfaces {
+		if evtType.Implements(iface) {
+			matching = append(matching, b.handlers[iface]...)
 		}
+	}
 
-		if handlerType.Kind() == reflect.Interface && evtType.Implements(handlerType) {
-			handlers = append(handlers, hh...)
+	sort.Slice(matching, func(i, j int) bool {
+		if matching[i].priority != matching[j].priority {
+			return matching[i].priority > matching[j].priority
 		}
+		return matching[i].seq < matching[j].seq
+	})
+
+	if b.matching == nil {
+		b.matching = map[reflect.Type][]registeredHandler{}
 	}
+	b.matching[evtType] = matching
 
-	return handlers
+	return matching
 }
 
-func (b *Brain) executeEventHandler(ctx context.Context, handler eventHandler, event reflect.Value) error {
+// executeTracedEventHandler executes the handler and traces it if the Brain
+// has a Tracer.
+func (b *Brain) executeTracedEventHandler(ctx context.Context, handler registeredHandler, typ reflect.Type, event reflect.Value) error {
+	if b.tracer == nil {
+		return b.executeEventHandler(ctx, handler, event)
+	}
+
+	ctx, end := b.tracer.StartHandler(ctx, typ.String(), handler.name)
+	err := b.executeEventHandler(ctx, handler, event)
+	end(err)
+
+	return err
+}
+
+// executeEventHandler runs the handler and retries it if it fails and was
+// registered with the HandlerRetry(…) option. The handler timeout applies to
+// all attempts together.
+func (b *Brain) executeEventHandler(ctx context.Context, handler registeredHandler, event reflect.Value) error {
+	var cancel context.CancelFunc
 	if b.handlerTimeout > 0 {
-		var cancel func()
 		ctx, cancel = context.WithTimeout(ctx, b.handlerTimeout)
-		defer cancel()
+	} else {
+		// The context of a handler that detaches from the event loop must be
+		// canceled when the Brain shuts down, even without a timeout.
+		ctx, cancel = context.WithCancel(ctx)
+	}
+
+	err := b.executeEventHandlerAttempts(ctx, handler, event, cancel)
+	if err == errHandlerDetached {
+		// The handler is still running and cancels its context when it returns
+		// or when the Brain shuts down.
+		return nil
+	}
+
+	cancel()
+	return err
+}
+
+// executeEventHandlerAttempts executes the handler until it succeeds or all
+// attempts of HandlerRetry(…) are used up.
+func (b *Brain) executeEventHandlerAttempts(ctx context.Context, handler registeredHandler, event reflect.Value, cancel func()) error {
+	backoff, waited := handler.backoff, time.Duration(0)
+	for i := 1; ; i++ {
+		err := b.executeEventHandlerOnce(ctx, handler, event, cancel)
+		if err == nil || err == errHandlerDetached || i >= handler.attempts || ctx.Err() != nil || !isRetryable(err) {
+			return err
+		}
+
+		waited += backoff
+		if b.handlerTimeout > 0 && waited >= b.handlerTimeout {
+			// The handler would time out before the next attempt anyway so
+			// we do not block the event loop any longer.
+			return err
+		}
+
+		b.logger.Info("Event handler failed, retrying",
+			zap.String("handler", handler.name),
+			zap.Int("attempt", i),
+			zap.Duration("backoff", backoff),
+			zap.Error(err),
+		)
+
+		timer := b.clock.NewTimer(backoff)
+		select {
+		case <-timer.C():
+			backoff *= 2
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
 	}
+}
 
-	done := make(chan error)
+// executeEventHandlerOnce runs the handler in a new goroutine and waits until
+// it returns, until it detaches itself (see detachHandler(…)) or until its
+// context is done. Handlers must respect the cancellation of their context
+// because they cannot be stopped from the outside. If they do not, their
+// goroutine keeps running in the background and they are reported via
+// Brain.logRunningHandlers(…) when the Brain shuts down. The release function
+// cancels the context of the handler. It is called when a detached handler
+// returns or when the Brain shuts down while it is still running.
+func (b *Brain) executeEventHandlerOnce(ctx context.Context, handler registeredHandler, event reflect.Value, release func()) error {
+	var detachOnce sync.Once
+	detached := make(chan struct{})
+	ctx = context.WithValue(ctx, ctxKeyDetach, func() {
+		detachOnce.Do(func() { close(detached) })
+	})
+
+	run := b.running.start(handler.name)
+	done := make(chan error, 1) // buffered so the goroutine can exit even if nobody is waiting anymore
 	go func() {
-		done <- handler(ctx, event)
+		err := handler.handle(ctx, event)
+		b.running.finish(run)
+		done <- err
 	}()
 
 	select {
 	case err := <-done:
 		return err
+	case <-detached:
+		go func() {
+			var err error
+			select {
+			case err = <-done:
+			case <-b.closing:
+				// Nobody would handle the result of the handler once the
+				// Brain shut down so we tell it to stop (e.g. to stop waiting
+				// for a reply in Message.Await(…)).
+				release()
+				err = <-done
+			}
+
+			if err != nil {
+				b.logger.Error("Detached event handler failed",
+					zap.String("handler", handler.name),
+					zap.Error(err),
+				)
+			}
+			release()
+		}()
+		return errHandlerDetached
 	case <-ctx.Done():
 		return ctx.Err()
 	}
@@ -376,25 +952,35 @@ func (b *Brain) executeEventHandler(ctx context.Context, handler eventHandler, e
 // pending events or handlers and instead exit immediately (e.g. after a timeout
 // or a second SIGTERM).
 func (b *Brain) Shutdown(ctx context.Context) {
+	b.shutdownWithReason(ctx, ShutdownRequested)
+}
+
+// shutdownWithReason is like Brain.Shutdown(…) but passes the given reason to
+// the handlers of the ShutdownEvent.
+func (b *Brain) shutdownWithReason(ctx context.Context, reason ShutdownReason) {
 	closing := atomic.CompareAndSwapInt32(&b.closed, 0, 1)
 	if !closing {
 		// brain is already shutting down
 		return
 	}
 
+	close(b.closing)
+
 	if !b.isHandlingEvents() {
 		// If the event handler loop is not running we must close the inputs
 		// channel from here and drain all pending requests in order to make
 		// b.consumeEvents() exit.
-		close(b.eventsInput)
+		b.closeInput()
 		for {
 			select {
-			case _, ok := <-b.eventsLoop:
+			case evt, ok := <-b.eventsLoop:
 				if !ok {
 					// The eventsLoop channel is closed in b.consumeEvents after
 					// all pending messages have been written to it.
 					return
 				}
+
+				b.releaseSlot(evt)
 			case <-ctx.Done():
 				// shutdown context is expired so we return without waiting for
 				// any pending events.
@@ -407,6 +993,7 @@ func (b *Brain) Shutdown(ctx context.Context) {
 	// proper cleanup and processing of pending messages over there.
 	req := shutdownRequest{
 		ctx:      ctx,
+		reason:   reason,
 		callback: make(chan bool),
 	}
 
@@ -414,6 +1001,106 @@ func (b *Brain) Shutdown(ctx context.Context) {
 	<-req.callback
 }
 
+// logRunningHandlers logs a warning for each event handler that is still
+// running. This is called when the Brain shuts down to find handlers which do
+// not respect the cancellation of their context and thus leak goroutines.
+func (b *Brain) logRunningHandlers(ctx context.Context) {
+	// Handlers that respect their context may just be returning right now so
+	// we give them a moment before we report them.
+	b.running.wait(ctx, runningHandlersGracePeriod)
+
+	for _, run := range b.running.list() {
+		b.logger.Warn("Event handler is still running after shutdown",
+			zap.String("handler", run.name),
+			zap.Duration("running_since", time.Since(run.started)),
+		)
+	}
+}
+
+// runningHandlers keeps track of all event handlers that are currently
+// executed, including handlers that were abandoned because their context was
+// done but which did not return yet.
+type runningHandlers struct {
+	mu       sync.Mutex
+	handlers map[*runningHandler]bool
+	finished chan struct{} // created by wait(…) and closed when the next handler finishes
+}
+
+// runningHandlersGracePeriod is the time the Brain waits for running handlers
+// to return during shutdown before it reports them.
+const runningHandlersGracePeriod = 100 * time.Millisecond
+
+type runningHandler struct {
+	name    string
+	started time.Time
+}
+
+func (r *runningHandlers) start(name string) *runningHandler {
+	run := &runningHandler{name: name, started: time.Now()}
+
+	r.mu.Lock()
+	if r.handlers == nil {
+		r.handlers = map[*runningHandler]bool{}
+	}
+	r.handlers[run] = true
+	r.mu.Unlock()
+
+	return run
+}
+
+func (r *runningHandlers) finish(run *runningHandler) {
+	r.mu.Lock()
+	delete(r.handlers, run)
+	if r.finished != nil {
+		// Only allocate a new channel if somebody is actually waiting.
+		close(r.finished)
+		r.finished = nil
+	}
+	r.mu.Unlock()
+}
+
+// wait blocks until no handler is running anymore, until the timeout is
+// reached or until the context is done.
+func (r *runningHandlers) wait(ctx context.Context, timeout time.Duration) {
+	deadline := time.After(timeout)
+	for {
+		r.mu.Lock()
+		if r.finished == nil {
+			r.finished = make(chan struct{})
+		}
+		n, finished := len(r.handlers), r.finished
+		r.mu.Unlock()
+
+		if n == 0 {
+			return
+		}
+
+		select {
+		case <-finished:
+		case <-deadline:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// list returns all running handlers, starting with the one that runs longest.
+func (r *runningHandlers) list() []*runningHandler {
+	r.mu.Lock()
+	runs := make([]*runningHandler, 0, len(r.handlers))
+	for run := range r.handlers {
+		runs = append(runs, run)
+	}
+	r.mu.Unlock()
+
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].started.Before(runs[j].started)
+	})
+
+	return runs
+}
+
 func checkHandlerParams(handlerFunc reflect.Type) (evtType reflect.Type, withContext bool, err error) {
 	numParams := handlerFunc.NumIn()
 	if numParams == 0 || numParams > 2 {
@@ -487,6 +1174,16 @@ func newHandlerFunc(handler reflect.Value, withContext, returnsErr bool) eventHa
 	}
 }
 
+// handlerName returns the name of the given handler function.
+func handlerName(handler reflect.Value) string {
+	fun := runtime.FuncForPC(handler.Pointer())
+	if fun == nil {
+		return "unknown"
+	}
+
+	return fun.Name()
+}
+
 func firstExternalCaller() string {
 	const depth = 32
 	var pcs [depth]uintptr
@@ -3,8 +3,10 @@ package joe
 import (
 	"context"
 	"errors"
+	"reflect"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -27,6 +29,14 @@ func TestBrain_RegisterHandler(t *testing.T) {
 		fun interface{}
 		err string
 	}{
+		"err_nil": {
+			fun: nil,
+			err: "event handler is no function",
+		},
+		"err_no_function": {
+			fun: "foo",
+			err: "event handler is no function",
+		},
 		"err_no_arg": {
 			fun: func() {},
 			err: "event handler needs one or two arguments",
@@ -109,6 +119,7 @@ func TestBrain_RegisterHandler(t *testing.T) {
 			b.RegisterHandler(c.fun)
 
 			if c.err != "" {
+				assert.EqualError(t, ValidateHandler(c.fun), c.err)
 				require.Len(t, b.registrationErrs, 1)
 				err := b.registrationErrs[0].Error()
 				if !strings.HasSuffix(err, c.err) {
@@ -118,6 +129,7 @@ func TestBrain_RegisterHandler(t *testing.T) {
 			}
 
 			require.Empty(t, b.registrationErrs, "unexpected registration errors")
+			assert.NoError(t, ValidateHandler(c.fun))
 
 			// Start the brains event handler loop.
 			go b.HandleEvents()
@@ -167,7 +179,10 @@ func TestBrain_HandlerErrors(t *testing.T) {
 
 	expectedLog := observer.LoggedEntry{
 		Entry:   zapcore.Entry{Level: zap.ErrorLevel, Message: "Event handler failed"},
-		Context: []zapcore.Field{zap.Error(handlerErr)},
+		Context: []zapcore.Field{
+			zap.String("handler", "github.com/go-joe/joe.TestBrain_HandlerErrors.func1"),
+			zap.Error(handlerErr),
+		},
 	}
 
 	handlerErrLogs := logs.FilterMessage(expectedLog.Message).AllUntimed()
@@ -241,6 +256,80 @@ func TestBrain_Emit_Scalar(t *testing.T) {
 	assert.True(t, handlerExecuted)
 }
 
+func TestBrain_OnEventDone(t *testing.T) {
+	type TestEvent struct{}
+
+	b := NewBrain(zaptest.NewLogger(t))
+
+	var sequence []string
+	finished := make(chan bool)
+	b.RegisterHandler(func(ctx context.Context, _ TestEvent) {
+		sequence = append(sequence, "h1")
+		OnEventDone(ctx, func() {
+			sequence = append(sequence, "done1")
+			close(finished)
+		})
+		OnEventDone(ctx, func() {
+			sequence = append(sequence, "done2")
+		})
+	})
+	b.RegisterHandler(func(ctx context.Context, _ TestEvent) {
+		sequence = append(sequence, "h2")
+	})
+
+	go b.HandleEvents()
+	defer b.Shutdown(ctx)
+
+	b.Emit(TestEvent{}, func(Event) {
+		sequence = append(sequence, "callback")
+	})
+
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+
+	assert.Equal(t, []string{"h1", "h2", "callback", "done2", "done1"}, sequence)
+
+	// OnEventDone must not panic outside of an event handler.
+	OnEventDone(ctx, func() { t.Error("should never be called") })
+}
+
+func TestBrain_Emit_Nil(t *testing.T) {
+	type TestEvent struct{}
+
+	obs, logs := observer.New(zap.DebugLevel)
+	b := NewBrain(zap.New(obs))
+
+	var handled, nilEvents int
+	b.RegisterHandler(func(TestEvent) {
+		handled++
+	})
+	b.RegisterHandler(func(evt interface{}) {
+		if evt == nil {
+			nilEvents++
+		}
+	})
+
+	go b.HandleEvents()
+	defer b.Shutdown(ctx)
+
+	b.Emit(nil)
+	b.EmitCtx(ctx, nil)
+	assert.True(t, errors.Is(b.EmitBlocking(ctx, nil), ErrNilEvent))
+	_, err := b.Request(ctx, nil)
+	assert.True(t, errors.Is(err, ErrNilEvent))
+
+	b.handleEvent(ctx, Event{Data: nil})
+
+	// The brain should still be processing events.
+	EmitSync(b, TestEvent{})
+	assert.Equal(t, 1, handled)
+	assert.Equal(t, 0, nilEvents)
+	assert.Len(t, logs.FilterMessage("Ignoring nil event").All(), 3)
+}
+
 func TestBrain_HandlerPanics(t *testing.T) {
 	type TestEvent struct{}
 
@@ -273,6 +362,8 @@ func TestBrain_HandlerPanics(t *testing.T) {
 			assert.Equal(t, zapcore.ErrorType, field.Type)
 			err := field.Interface.(error)
 			assert.EqualError(t, err, "handler panic: something went horribly wrong")
+		case "handler":
+			assert.Equal(t, "github.com/go-joe/joe.TestBrain_HandlerPanics.func1", field.String)
 		default:
 			t.Errorf("unexpected field %q in log entry", field.Key)
 		}
@@ -447,6 +538,235 @@ func TestBrain_RegisterMultiple(t *testing.T) {
 	assert.Equal(t, []string{"h1", "h2", "h3", "h4"}, execSequence)
 }
 
+func TestBrain_RegisterAfterEmit(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	b := NewBrain(logger)
+
+	type TestEvent struct{}
+
+	var execSequence []string // tracks order of handler execution
+	b.RegisterHandler(func(TestEvent) {
+		execSequence = append(execSequence, "h1")
+	})
+
+	go b.HandleEvents()
+	defer b.Shutdown(ctx)
+
+	EmitSync(b, TestEvent{})
+	assert.Equal(t, []string{"h1"}, execSequence)
+
+	// Handlers that are registered later must also be executed even though
+	// the handlers of the event type are cached already.
+	b.RegisterHandler(func(interface{}) {
+		execSequence = append(execSequence, "h2")
+	})
+
+	EmitSync(b, TestEvent{})
+	assert.Equal(t, []string{"h1", "h1", "h2"}, execSequence)
+}
+
+// channeled is implemented by multiple event types in TestBrain_InterfaceHandler.
+type channeled interface {
+	GetChannel() string
+}
+
+type channelEventA struct{ Channel string }
+type channelEventB struct{ Name string }
+type channelEventC struct{ Channel string } // implements channeled via pointer receiver
+
+func (e channelEventA) GetChannel() string  { return e.Channel }
+func (e channelEventB) GetChannel() string  { return "#" + e.Name }
+func (e *channelEventC) GetChannel() string { return e.Channel }
+
+func TestBrain_InterfaceHandler(t *testing.T) {
+	type OtherEvent struct{}
+
+	b := NewBrain(zaptest.NewLogger(t))
+
+	var channels []string
+	var events []interface{}
+	b.RegisterHandler(func(evt channeled) {
+		channels = append(channels, evt.GetChannel())
+		events = append(events, evt)
+	})
+
+	go b.HandleEvents()
+	defer b.Shutdown(ctx)
+
+	EmitSync(b, channelEventA{Channel: "foo"})
+	EmitSync(b, channelEventB{Name: "bar"})
+	EmitSync(b, &channelEventC{Channel: "baz"})
+	EmitSync(b, channelEventC{Channel: "ignored"}) // only *channelEventC implements the interface
+	EmitSync(b, OtherEvent{})
+
+	assert.Equal(t, []string{"foo", "#bar", "baz"}, channels)
+	assert.Equal(t, []interface{}{
+		channelEventA{Channel: "foo"},
+		channelEventB{Name: "bar"},
+		&channelEventC{Channel: "baz"},
+	}, events, "handler should receive the original concrete events")
+}
+
+func TestBrain_HandlerPriority(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	b := NewBrain(logger)
+
+	type TestEvent struct{}
+
+	var execSequence []string
+	handler := func(name string) func(TestEvent) {
+		return func(TestEvent) {
+			execSequence = append(execSequence, name)
+		}
+	}
+
+	b.RegisterHandler(handler("h1"))
+	b.RegisterHandler(handler("h2"), HandlerPriority(-1))
+	b.RegisterHandler(handler("h3"), HandlerPriority(10))
+	b.RegisterHandler(handler("h4"))
+	b.RegisterHandler(handler("h5"), HandlerPriority(10))
+	b.RegisterHandler(func(evt interface{}) {
+		if _, ok := evt.(TestEvent); ok { // ignore the InitEvent
+			execSequence = append(execSequence, "h6")
+		}
+	}, HandlerPriority(5))
+	require.Empty(t, b.registrationErrs, "unexpected registration errors")
+
+	go b.HandleEvents()
+	defer b.Shutdown(ctx)
+
+	EmitSync(b, TestEvent{})
+	assert.Equal(t, []string{"h3", "h5", "h6", "h1", "h4", "h2"}, execSequence)
+}
+
+func TestBrain_HandlerRetry(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	b := NewBrain(logger)
+
+	type TestEvent struct{}
+
+	var attempts int
+	b.RegisterHandler(func(TestEvent) error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("flaky")
+		}
+		return nil
+	}, HandlerRetry(5, time.Millisecond))
+
+	var otherCalls int
+	b.RegisterHandler(func(TestEvent) {
+		otherCalls++
+	})
+	require.Empty(t, b.registrationErrs, "unexpected registration errors")
+
+	go b.HandleEvents()
+	defer b.Shutdown(ctx)
+
+	EmitSync(b, TestEvent{})
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, 1, otherCalls, "only the failed handler should be retried")
+}
+
+func TestBrain_HandlerRetry_Exhausted(t *testing.T) {
+	cases := map[string]struct {
+		err      error
+		attempts int
+	}{
+		"retryable":     {err: errors.New("flaky"), attempts: 3},
+		"not_retryable": {err: retryableError{retryable: false}, attempts: 1},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			logger := zaptest.NewLogger(t)
+			b := NewBrain(logger)
+
+			type TestEvent struct{}
+
+			var attempts int
+			b.RegisterHandler(func(TestEvent) error {
+				attempts++
+				return c.err
+			}, HandlerRetry(3, time.Millisecond))
+			require.Empty(t, b.registrationErrs, "unexpected registration errors")
+
+			go b.HandleEvents()
+			defer b.Shutdown(ctx)
+
+			EmitSync(b, TestEvent{})
+			assert.Equal(t, c.attempts, attempts)
+		})
+	}
+}
+
+func TestBrain_HandlerRetry_Timeout(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	b := NewBrain(logger)
+	b.handlerTimeout = 50 * time.Millisecond
+
+	type TestEvent struct{}
+
+	var attempts int32
+	b.RegisterHandler(func(TestEvent) error {
+		atomic.AddInt32(&attempts, 1)
+		return errors.New("flaky")
+	}, HandlerRetry(100, time.Hour))
+	require.Empty(t, b.registrationErrs, "unexpected registration errors")
+
+	go b.HandleEvents()
+	defer b.Shutdown(ctx)
+
+	EmitSync(b, TestEvent{})
+	assert.Equal(t, int32(1), atomic.LoadInt32(&attempts), "the backoff should be interrupted by the handler timeout")
+}
+
+func TestBrain_HandlerRetry_Backoff(t *testing.T) {
+	cases := map[string]struct {
+		timeout  time.Duration
+		attempts int
+		waits    []time.Duration
+	}{
+		"no_timeout": {attempts: 4, waits: []time.Duration{time.Hour, 2 * time.Hour, 4 * time.Hour}},
+		"timeout":    {timeout: 5 * time.Hour, attempts: 3, waits: []time.Duration{time.Hour, 2 * time.Hour}},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			logger := zaptest.NewLogger(t)
+			clock := new(instantClock)
+			b := NewBrain(logger)
+			b.handlerTimeout = c.timeout
+			b.clock = clock
+
+			type TestEvent struct{}
+
+			var attempts int
+			b.RegisterHandler(func(TestEvent) error {
+				attempts++
+				return errors.New("flaky")
+			}, HandlerRetry(4, time.Hour))
+			require.Empty(t, b.registrationErrs, "unexpected registration errors")
+
+			go b.HandleEvents()
+			defer b.Shutdown(ctx)
+
+			EmitSync(b, TestEvent{})
+			assert.Equal(t, c.attempts, attempts)
+			assert.Equal(t, c.waits, clock.waits)
+		})
+	}
+}
+
+func TestBrain_HandlerRetry_Invalid(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	b := NewBrain(logger)
+
+	b.RegisterHandler(func(InitEvent) {}, HandlerRetry(0, time.Second))
+	require.Len(t, b.registrationErrs, 1)
+	assert.Contains(t, b.registrationErrs[0].Error(), "handler retry attempts must be at least one")
+}
+
 // TestFinishEventContent tests that handlers can mark an event as processed to
 // avoid later handlers to be executed on the given event.
 func TestFinishEventContent(t *testing.T) {
@@ -473,17 +793,353 @@ func TestFinishEventContent(t *testing.T) {
 	go b.HandleEvents()
 	defer b.Shutdown(ctx)
 
-	EmitSync(b, TestEvent{})
+	evt := EmitSync(b, TestEvent{})
 	assert.True(t, h1Executed, "first handler should have been executed")
 	assert.False(t, h2Executed, "second handler should not have been executed")
+	assert.True(t, evt.AbortEarly, "callbacks should see that the event was aborted")
+	assert.Contains(t, evt.AbortedBy, "TestFinishEventContent.func1")
+}
+
+func TestBrain_HandlerErrorPolicy(t *testing.T) {
+	errFatal := errors.New("fatal")
+	cases := map[string]struct {
+		policy     HandlerErrorPolicy
+		err        error
+		h2Executed bool
+	}{
+		"default":            {policy: nil, err: errFatal, h2Executed: true},
+		"continue":           {policy: ContinueOnError, err: errFatal, h2Executed: true},
+		"abort":              {policy: AbortOnError, err: errFatal, h2Executed: false},
+		"abort_without_err":  {policy: AbortOnError, err: nil, h2Executed: true},
+		"custom_abort":       {policy: abortOn(errFatal), err: errFatal, h2Executed: false},
+		"custom_other_error": {policy: abortOn(errFatal), err: errors.New("other"), h2Executed: true},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			logger := zaptest.NewLogger(t)
+			b := NewBrain(logger)
+			b.errorPolicy = c.policy
+
+			type TestEvent struct{}
+
+			b.RegisterHandler(func(TestEvent) error {
+				return c.err
+			})
+
+			var h2Executed bool
+			b.RegisterHandler(func(TestEvent) {
+				h2Executed = true
+			})
+			require.Empty(t, b.registrationErrs, "unexpected registration errors")
+
+			go b.HandleEvents()
+			defer b.Shutdown(ctx)
+
+			evt := EmitSync(b, TestEvent{})
+			assert.Equal(t, c.h2Executed, h2Executed)
+			assert.Equal(t, !c.h2Executed, evt.AbortEarly)
+		})
+	}
+}
+
+func abortOn(target error) HandlerErrorPolicy {
+	return func(err error) ErrorDecision {
+		if errors.Is(err, target) {
+			return AbortHandlers
+		}
+		return ContinueHandlers
+	}
+}
+
+func TestBrain_Request(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	b := NewBrain(logger)
+
+	type QueryEvent struct{ Key string }
+
+	b.RegisterHandler(func(ctx context.Context, evt QueryEvent) {
+		AddEventResult(ctx, "first: "+evt.Key)
+	})
+	b.RegisterHandler(func(QueryEvent) {
+		// this handler does not add any results
+	})
+	b.RegisterHandler(func(ctx context.Context, evt QueryEvent) {
+		AddEventResult(ctx, 42)
+	})
+	require.Empty(t, b.registrationErrs, "unexpected registration errors")
+
+	go b.HandleEvents()
+	defer b.Shutdown(ctx)
+
+	results, err := b.Request(ctx, QueryEvent{Key: "foo"})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"first: foo", 42}, results)
+}
+
+func TestBrain_Request_Context(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	b := NewBrain(logger)
+
+	type QueryEvent struct{}
+
+	// The event handler loop is never started so the request cannot complete.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	results, err := b.Request(ctx, QueryEvent{})
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Nil(t, results)
+
+	b.Shutdown(context.Background())
+	_, err = b.Request(ctx, QueryEvent{})
+	assert.True(t, errors.Is(err, ErrBrainClosed))
 }
 
 // EmitSync emits the given event on the brain and blocks until it has received
 // the context which indicates that the event was fully processed by all
 // matching handlers.
-func EmitSync(brain EventEmitter, event interface{}) {
-	done := make(chan bool)
-	callback := func(Event) { done <- true }
+func EmitSync(brain EventEmitter, event interface{}) Event {
+	done := make(chan Event)
+	callback := func(evt Event) { done <- evt }
 	brain.Emit(event, callback)
+	return <-done
+}
+
+func TestBrain_RunningHandlersAfterShutdown(t *testing.T) {
+	type TestEvent struct{}
+
+	obs, logs := observer.New(zap.DebugLevel)
+	b := NewBrain(zap.New(obs))
+	b.handlerTimeout = 10 * time.Millisecond
+
+	release := make(chan bool)
+	defer close(release)
+
+	b.RegisterHandler(func(TestEvent) {
+		<-release // ignores its context
+	})
+	b.RegisterHandler(func(ctx context.Context, _ TestEvent) {
+		<-ctx.Done() // respects its context
+	})
+
+	go b.HandleEvents()
+	EmitSync(b, TestEvent{})
+	b.Shutdown(ctx)
+
+	warnings := logs.FilterMessage("Event handler is still running after shutdown").All()
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0].ContextMap()["handler"], "TestBrain_RunningHandlersAfterShutdown.func1")
+}
+
+func TestBrain_EmitCtx(t *testing.T) {
+	type TestEvent struct{}
+	type ctxKey string
+
+	b := NewBrain(zaptest.NewLogger(t))
+
+	values := make(chan interface{}, 1)
+	b.RegisterHandler(func(ctx context.Context, _ TestEvent) {
+		values <- ctx.Value(ctxKey("trace"))
+	})
+
+	go b.HandleEvents()
+	defer b.Shutdown(ctx)
+
+	done := make(chan bool)
+	callerCtx := context.WithValue(ctx, ctxKey("trace"), "42")
+	b.EmitCtx(callerCtx, TestEvent{}, func(Event) { done <- true })
 	<-done
+	assert.Equal(t, "42", <-values)
+
+	EmitSync(b, TestEvent{})
+	assert.Nil(t, <-values, "context values should not leak into other events")
+}
+
+func TestBrain_EmitCtx_Canceled(t *testing.T) {
+	type TestEvent struct{}
+
+	b := NewBrain(zaptest.NewLogger(t))
+
+	errs := make(chan error, 1)
+	b.RegisterHandler(func(ctx context.Context, _ TestEvent) error {
+		<-ctx.Done()
+		errs <- ctx.Err()
+		return nil
+	})
+
+	go b.HandleEvents()
+	defer b.Shutdown(ctx)
+
+	callerCtx, cancel := context.WithCancel(ctx)
+	b.EmitCtx(callerCtx, TestEvent{})
+	cancel()
+
+	select {
+	case err := <-errs:
+		assert.Equal(t, context.Canceled, err)
+	case <-time.After(time.Second):
+		t.Fatal("handler context was not canceled")
+	}
+}
+
+func TestBrain_EmitCtx_Shutdown(t *testing.T) {
+	type TestEvent struct{}
+
+	b := NewBrain(zaptest.NewLogger(t))
+
+	deadlines := make(chan time.Time, 1)
+	errs := make(chan error, 1)
+	b.RegisterHandler(func(ctx context.Context, _ TestEvent) {
+		deadline, _ := ctx.Deadline()
+		deadlines <- deadline
+		<-ctx.Done()
+		errs <- ctx.Err()
+	})
+
+	// The loop context has a deadline during a shutdown which must also apply
+	// to events that were emitted with their own context.
+	loopCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+
+	callerCtx, cancelCaller := context.WithTimeout(ctx, time.Hour)
+	defer cancelCaller()
+
+	loopDeadline, _ := loopCtx.Deadline()
+	b.handleEvent(loopCtx, Event{Data: TestEvent{}, ctx: callerCtx})
+	assert.Equal(t, loopDeadline, <-deadlines)
+	assert.Error(t, <-errs)
+	assert.NoError(t, callerCtx.Err())
+}
+
+func TestBrain_EmitBlocking(t *testing.T) {
+	type TestEvent struct{ N int }
+
+	b := NewBrain(zaptest.NewLogger(t))
+	b.queueSlots = make(chan struct{}, 2)
+
+	var mu sync.Mutex
+	var handled []int
+	b.RegisterHandler(func(evt TestEvent) {
+		mu.Lock()
+		handled = append(handled, evt.N)
+		mu.Unlock()
+	})
+
+	// The event handler loop is not running yet so the queue fills up.
+	require.NoError(t, b.EmitBlocking(ctx, TestEvent{1}))
+	require.NoError(t, b.EmitBlocking(ctx, TestEvent{2}))
+	b.Emit(TestEvent{3}) // never blocks
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	err := b.EmitBlocking(timeoutCtx, TestEvent{4})
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	go b.HandleEvents()
+	require.NoError(t, b.EmitBlocking(ctx, TestEvent{5}), "should not block once events are processed")
+	EmitSync(b, TestEvent{6})
+	b.Shutdown(ctx)
+
+	assert.Equal(t, []int{1, 2, 3, 5, 6}, handled)
+	assert.Len(t, b.queueSlots, 0, "all slots should be released")
+
+	err = b.EmitBlocking(ctx, TestEvent{7})
+	assert.True(t, errors.Is(err, ErrBrainClosed))
+}
+
+func TestBrain_EmitBlocking_Shutdown(t *testing.T) {
+	type BlockingEvent struct{}
+	type TestEvent struct{}
+
+	b := NewBrain(zaptest.NewLogger(t))
+	b.queueSlots = make(chan struct{}, 1)
+
+	unblock := make(chan bool)
+	b.RegisterHandler(func(BlockingEvent) {
+		<-unblock
+	})
+
+	go b.HandleEvents()
+	for !b.isHandlingEvents() {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Keep the event loop busy so the next event stays in the full queue.
+	b.Emit(BlockingEvent{})
+	require.NoError(t, b.EmitBlocking(ctx, TestEvent{}))
+
+	// The second emitter blocks until the slot of the first event is released,
+	// which may only happen after the Brain started to shut down.
+	errs := make(chan error)
+	go func() {
+		errs <- b.EmitBlocking(ctx, TestEvent{})
+	}()
+
+	time.Sleep(10 * time.Millisecond) // give the emitter time to block
+
+	shutdownDone := make(chan bool)
+	go func() {
+		b.Shutdown(ctx)
+		close(shutdownDone)
+	}()
+
+	for !b.isClosed() {
+		time.Sleep(time.Millisecond)
+	}
+
+	close(unblock)
+	assert.True(t, errors.Is(<-errs, ErrBrainClosed))
+	<-shutdownDone
+	assert.Len(t, b.queueSlots, 0, "all slots should be released")
+}
+
+func TestBrain_EmitBlocking_Unlimited(t *testing.T) {
+	type TestEvent struct{}
+
+	b := NewBrain(zaptest.NewLogger(t))
+	for i := 0; i < 100; i++ {
+		require.NoError(t, b.EmitBlocking(ctx, TestEvent{}))
+	}
+}
+
+func BenchmarkBrain_DetermineHandlers(b *testing.B) {
+	type BenchmarkEvent struct{}
+
+	brain := NewBrain(zap.NewNop())
+	brain.RegisterHandler(func(BenchmarkEvent) {})
+	brain.RegisterHandler(func(interface{}) {})
+
+	// Register handlers for many other event types to simulate a bot that uses
+	// lots of different events.
+	for i := 0; i < 200; i++ {
+		evtType := reflect.ArrayOf(i, reflect.TypeOf(BenchmarkEvent{}))
+		funType := reflect.FuncOf([]reflect.Type{evtType}, nil, false)
+		fun := reflect.MakeFunc(funType, func([]reflect.Value) []reflect.Value { return nil })
+		brain.RegisterHandler(fun.Interface())
+	}
+
+	evtType := reflect.TypeOf(BenchmarkEvent{})
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		handlers := brain.determineHandlers(evtType)
+		if len(handlers) != 2 {
+			b.Fatalf("expected 2 handlers but got %d", len(handlers))
+		}
+	}
+}
+
+func BenchmarkBrain_HandleEvent(b *testing.B) {
+	type BenchmarkEvent struct{ N int }
+
+	brain := NewBrain(zap.NewNop())
+	brain.RegisterHandler(func(BenchmarkEvent) {})
+	brain.RegisterHandler(func(context.Context, BenchmarkEvent) error { return nil })
+	brain.RegisterHandler(func(context.Context, interface{}) {})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		brain.handleEvent(ctx, Event{Data: BenchmarkEvent{N: i}})
+	}
 }
@@ -0,0 +1,192 @@
+package joe
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// CachingMemory is a Memory that wraps another Memory and caches the results of
+// Memory.Get(…) in-process. This is useful if the wrapped Memory connects to an
+// external backend (e.g. redis) and the bot reads the same keys very often
+// (e.g. permissions which are checked for each command).
+//
+// Cached entries expire after a configurable TTL and the least recently used
+// entries are evicted if the cache is full. An entry is invalidated when its
+// key is changed or deleted via the CachingMemory. Changes that other processes
+// make to the wrapped Memory however are only noticed when the cached entry
+// expires.
+type CachingMemory struct {
+	Memory
+
+	ttl        time.Duration
+	maxEntries int // zero means no limit
+	clock      Clock
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // values are *cacheEntry, most recently used first
+	gen     uint64     // incremented on each invalidation to detect concurrent writes
+}
+
+type cacheEntry struct {
+	key     string
+	value   []byte
+	exists  bool // false if the key did not exist in the wrapped Memory
+	expires time.Time
+}
+
+// NewCachingMemory returns a new CachingMemory that caches the values of the
+// given Memory for the duration of the ttl. If maxEntries is larger than zero,
+// at most that many keys are cached.
+func NewCachingMemory(m Memory, ttl time.Duration, maxEntries int) *CachingMemory {
+	return &CachingMemory{
+		Memory:     m,
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		clock:      realClock{},
+		entries:    map[string]*list.Element{},
+		lru:        list.New(),
+	}
+}
+
+// WithMemoryCache is an option to cache the values of the Memory of the bot via
+// a CachingMemory with the given ttl and maximum number of entries. The cache
+// wraps the final Memory of the bot so this option can be passed to joe.New(…)
+// in any order.
+func WithMemoryCache(ttl time.Duration, maxEntries int) Module {
+	return ModuleFunc(func(conf *Config) error {
+		if ttl <= 0 {
+			return errors.New("memory cache ttl must be positive")
+		}
+
+		if maxEntries < 0 {
+			return errors.New("memory cache size must not be negative")
+		}
+
+		conf.memoryCacheTTL = ttl
+		conf.memoryCacheSize = maxEntries
+		return nil
+	})
+}
+
+// Get returns the cached value of the key or reads it from the wrapped Memory
+// if it is not cached or its cached value expired.
+func (c *CachingMemory) Get(key string) ([]byte, bool, error) {
+	return c.GetCtx(context.Background(), key)
+}
+
+// GetCtx implements the ContextMemory interface. The context is passed to the
+// wrapped Memory if it implements the ContextMemory interface as well.
+func (c *CachingMemory) GetCtx(ctx context.Context, key string) ([]byte, bool, error) {
+	now := c.clock.Now()
+
+	c.mu.Lock()
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*cacheEntry)
+		if now.Before(entry.expires) {
+			c.lru.MoveToFront(elem)
+			c.mu.Unlock()
+			return entry.value, entry.exists, nil
+		}
+
+		c.remove(elem)
+	}
+	gen := c.gen
+	c.mu.Unlock()
+
+	value, ok, err := memoryGet(ctx, c.Memory, key)
+	if err != nil {
+		return nil, false, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// If any key was invalidated while we were reading from the wrapped
+	// Memory, the value we read may already be outdated so we do not cache it.
+	if c.gen == gen {
+		c.add(&cacheEntry{key: key, value: value, exists: ok, expires: now.Add(c.ttl)})
+	}
+
+	return value, ok, nil
+}
+
+// Set stores the value in the wrapped Memory and invalidates the cached value.
+func (c *CachingMemory) Set(key string, value []byte) error {
+	return c.SetCtx(context.Background(), key, value)
+}
+
+// SetCtx implements the ContextMemory interface.
+func (c *CachingMemory) SetCtx(ctx context.Context, key string, value []byte) error {
+	defer c.invalidate(key)
+	return memorySet(ctx, c.Memory, key, value)
+}
+
+// Delete deletes the key from the wrapped Memory and invalidates the cached
+// value.
+func (c *CachingMemory) Delete(key string) (bool, error) {
+	return c.DeleteCtx(context.Background(), key)
+}
+
+// DeleteCtx implements the ContextMemory interface.
+func (c *CachingMemory) DeleteCtx(ctx context.Context, key string) (bool, error) {
+	defer c.invalidate(key)
+	return memoryDelete(ctx, c.Memory, key)
+}
+
+// CompareAndSwap implements the CASMemory interface by delegating to the
+// wrapped Memory if it implements it as well. Otherwise the swap is done via
+// Memory.Get(…) and Memory.Set(…) of the wrapped Memory. In both cases the
+// cached value is invalidated.
+func (c *CachingMemory) CompareAndSwap(key string, old, new []byte) (bool, error) {
+	defer c.invalidate(key)
+	if m, ok := c.Memory.(CASMemory); ok {
+		return m.CompareAndSwap(key, old, new)
+	}
+
+	return compareAndSwap(c.Memory, key, old, new)
+}
+
+// Ping implements the PingMemory interface by delegating to the wrapped Memory.
+// If the wrapped Memory does not implement the PingMemory interface, it is
+// assumed to be always available.
+func (c *CachingMemory) Ping() error {
+	m, ok := c.Memory.(PingMemory)
+	if !ok {
+		return nil
+	}
+
+	return m.Ping()
+}
+
+func (c *CachingMemory) invalidate(key string) {
+	c.mu.Lock()
+	c.gen++
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+	c.mu.Unlock()
+}
+
+// add inserts the entry and evicts the least recently used entries if the
+// cache is full. The caller must hold the lock.
+func (c *CachingMemory) add(entry *cacheEntry) {
+	if elem, ok := c.entries[entry.key]; ok {
+		c.remove(elem)
+	}
+
+	c.entries[entry.key] = c.lru.PushFront(entry)
+	for c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back())
+	}
+}
+
+// remove deletes the given element from the cache. The caller must hold the
+// lock.
+func (c *CachingMemory) remove(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*cacheEntry)
+	delete(c.entries, entry.key)
+}
@@ -0,0 +1,160 @@
+package joe
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// countingMemory counts all reads from the wrapped Memory.
+type countingMemory struct {
+	*inMemory
+	gets  int
+	delay time.Duration // simulates the latency of an external backend
+}
+
+func (m *countingMemory) Get(key string) ([]byte, bool, error) {
+	m.gets++
+	if m.delay > 0 {
+		time.Sleep(m.delay)
+	}
+	return m.inMemory.Get(key)
+}
+
+// stoppedClock is a Clock whose time only changes when the test sets it.
+type stoppedClock struct {
+	realClock
+	now time.Time
+}
+
+func (c *stoppedClock) Now() time.Time {
+	return c.now
+}
+
+func TestCachingMemory(t *testing.T) {
+	mem := &countingMemory{inMemory: newInMemory()}
+	clock := &stoppedClock{now: time.Now()}
+	cache := NewCachingMemory(mem, time.Minute, 0)
+	cache.clock = clock
+
+	require.NoError(t, cache.Set("foo", []byte("bar")))
+
+	for i := 0; i < 3; i++ {
+		value, ok, err := cache.Get("foo")
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "bar", string(value))
+	}
+	assert.Equal(t, 1, mem.gets, "only the first read should hit the memory")
+
+	// Missing keys are cached as well.
+	_, ok, err := cache.Get("missing")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	_, ok, _ = cache.Get("missing")
+	assert.False(t, ok)
+	assert.Equal(t, 2, mem.gets)
+
+	// Expired entries are read again.
+	clock.now = clock.now.Add(time.Minute)
+	value, _, _ := cache.Get("foo")
+	assert.Equal(t, "bar", string(value))
+	assert.Equal(t, 3, mem.gets)
+}
+
+func TestCachingMemory_Invalidation(t *testing.T) {
+	mem := &countingMemory{inMemory: newInMemory()}
+	cache := NewCachingMemory(mem, time.Hour, 0)
+
+	require.NoError(t, cache.Set("foo", []byte("bar")))
+	_, _, _ = cache.Get("foo")
+
+	require.NoError(t, cache.Set("foo", []byte("baz")))
+	value, ok, err := cache.Get("foo")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "baz", string(value))
+
+	swapped, err := cache.CompareAndSwap("foo", []byte("baz"), []byte("qux"))
+	require.NoError(t, err)
+	require.True(t, swapped)
+	value, _, _ = cache.Get("foo")
+	assert.Equal(t, "qux", string(value))
+
+	ok, err = cache.Delete("foo")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	_, ok, err = cache.Get("foo")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestCachingMemory_MaxEntries(t *testing.T) {
+	mem := &countingMemory{inMemory: newInMemory()}
+	cache := NewCachingMemory(mem, time.Hour, 2)
+
+	_, _, _ = cache.Get("a")
+	_, _, _ = cache.Get("b")
+	_, _, _ = cache.Get("a") // "b" is now the least recently used entry
+	_, _, _ = cache.Get("c")
+	assert.Equal(t, 3, mem.gets)
+
+	_, _, _ = cache.Get("a")
+	_, _, _ = cache.Get("c")
+	assert.Equal(t, 3, mem.gets)
+
+	_, _, _ = cache.Get("b")
+	assert.Equal(t, 4, mem.gets, "evicted entry should be read again")
+}
+
+func TestCachingMemory_Errors(t *testing.T) {
+	memErr := errors.New("connection refused")
+	cache := NewCachingMemory(failingMemory{inMemory: newInMemory(), err: memErr}, time.Hour, 0)
+
+	_, _, err := cache.Get("foo")
+	assert.Equal(t, memErr, err)
+	assert.Empty(t, cache.entries, "errors should not be cached")
+}
+
+func TestWithMemoryCache(t *testing.T) {
+	b := New("test", WithMemoryCache(time.Minute, 100))
+	require.NoError(t, b.initErr)
+	assert.IsType(t, new(CachingMemory), b.Store.memory)
+
+	b = New("test", WithMemoryCache(0, 100))
+	assert.EqualError(t, b.initErr, "memory cache ttl must be positive")
+
+	b = New("test", WithMemoryCache(time.Minute, -1))
+	assert.EqualError(t, b.initErr, "memory cache size must not be negative")
+}
+
+func BenchmarkCachingMemory(b *testing.B) {
+	logger := zap.NewNop()
+	for _, cached := range []bool{false, true} {
+		b.Run(fmt.Sprintf("cached=%v", cached), func(b *testing.B) {
+			var mem Memory = &countingMemory{inMemory: newInMemory(), delay: 10 * time.Microsecond}
+			if cached {
+				mem = NewCachingMemory(mem, time.Minute, 0)
+			}
+
+			store := NewStorage(logger)
+			store.SetMemory(mem)
+			auth := NewAuth(logger, store)
+			_, err := auth.Grant("admin", "alice")
+			require.NoError(b, err)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				err := auth.CheckPermission("admin.deploy", "alice")
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,8 @@
+package joe
+
+// Channel contains all the information about a channel of the chat.
+type Channel struct {
+	ID        string // corresponds to the ReceiveMessageEvent.Channel field
+	Name      string
+	IsPrivate bool // true if the channel is private or a direct message, if known by the Adapter
+}
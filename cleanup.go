@@ -0,0 +1,99 @@
+package joe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// storageCleanup is a cleanup that was registered via WithStorageCleanup(…).
+type storageCleanup struct {
+	interval  time.Duration
+	predicate func(key string) bool
+}
+
+// Cleanup deletes all keys for which the given predicate returns true and
+// returns how many keys have been deleted. This is useful to remove stale
+// entries that were written by a feature that stores transient data if the
+// Memory does not support expiring keys natively.
+//
+// The internal keys of the indexes (see Storage.Index(…)) are never passed to
+// the predicate. Instead, deleted keys are removed from their indexes the same
+// way as via Storage.Delete(…). If a key cannot be deleted, Cleanup stops and
+// returns the number of keys that have been deleted so far together with the
+// error.
+func (s *Storage) Cleanup(predicate func(key string) bool) (int, error) {
+	keys, err := s.Keys()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list keys: %w", err)
+	}
+
+	var n int
+	for _, key := range keys {
+		if isIndexKey(key) || !predicate(key) {
+			continue
+		}
+
+		ok, err := s.Delete(key)
+		if err != nil {
+			return n, fmt.Errorf("failed to delete key %q: %w", key, err)
+		}
+
+		if ok {
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// WithStorageCleanup is an option to periodically delete all keys from the
+// Storage for which the given predicate returns true (see Storage.Cleanup(…)).
+// The first cleanup runs after the first interval has passed while the bot is
+// running. Errors are logged and the cleanup is tried again after the next
+// interval. The option can be passed multiple times to register cleanups with
+// different intervals.
+func WithStorageCleanup(interval time.Duration, predicate func(key string) bool) Module {
+	return ModuleFunc(func(conf *Config) error {
+		if interval <= 0 {
+			return errors.New("storage cleanup interval must be positive")
+		}
+
+		if predicate == nil {
+			return errors.New("storage cleanup predicate must not be nil")
+		}
+
+		conf.storageCleanups = append(conf.storageCleanups, storageCleanup{
+			interval:  interval,
+			predicate: predicate,
+		})
+		return nil
+	})
+}
+
+// runStorageCleanup executes the cleanup after each interval until the context
+// is done.
+func (b *Bot) runStorageCleanup(ctx context.Context, cleanup storageCleanup) {
+	timer := b.clock.NewTimer(cleanup.interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C():
+		}
+
+		n, err := b.Store.Cleanup(cleanup.predicate)
+		if err != nil {
+			b.Logger.Error("Failed to clean up storage", zap.Int("deleted", n), zap.Error(err))
+		} else if n > 0 {
+			b.Logger.Info("Cleaned up storage", zap.Int("deleted", n))
+		}
+
+		timer.Reset(cleanup.interval)
+	}
+}
@@ -0,0 +1,87 @@
+package joe_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-joe/joe"
+	"github.com/go-joe/joe/joetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func isDedupKey(key string) bool {
+	return strings.HasPrefix(key, "dedup.")
+}
+
+func TestStorage_Cleanup(t *testing.T) {
+	store := joetest.NewStorage(t)
+	store.MustSet("dedup.1", true)
+	store.MustSet("dedup.2", true)
+	store.MustSet("reminders.1", "buy milk")
+	require.NoError(t, store.Index("by-user", "dedup.1", "alice"))
+	require.NoError(t, store.Index("by-user", "reminders.1", "alice"))
+
+	n, err := store.Cleanup(isDedupKey)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	keys, err := store.IndexMembers("by-user", "alice")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"reminders.1"}, keys, "deleted keys should be removed from their indexes")
+
+	ok, err := store.Has("reminders.1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	n, err = store.Cleanup(func(key string) bool {
+		assert.False(t, strings.HasPrefix(key, "joe.index"), "index keys should not be passed to the predicate")
+		return false
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestWithStorageCleanup(t *testing.T) {
+	clock := joetest.NewClock(time.Now())
+	b := joetest.NewBot(t,
+		joe.WithClock(clock),
+		joe.WithStorageCleanup(time.Hour, isDedupKey),
+	)
+
+	require.NoError(t, b.Store.Set("dedup.1", true))
+	require.NoError(t, b.Store.Set("settings", "foo"))
+
+	b.Start()
+	defer b.Stop()
+
+	keys := func() []string {
+		keys, err := b.Store.Keys()
+		require.NoError(t, err)
+		return keys
+	}
+
+	for i := 0; i < 2; i++ {
+		require.Eventually(t, func() bool { return clock.Timers() == 1 }, time.Second, time.Millisecond)
+		assert.Contains(t, keys(), "dedup.1")
+
+		clock.Advance(time.Hour)
+		assert.Eventually(t, func() bool {
+			return len(keys()) == 1
+		}, time.Second, time.Millisecond, "the cleanup should run after each interval")
+		assert.Equal(t, []string{"settings"}, keys())
+
+		require.NoError(t, b.Store.Set("dedup.1", true))
+	}
+}
+
+func TestWithStorageCleanup_Errors(t *testing.T) {
+	b := joetest.NewBot(t, joe.WithStorageCleanup(0, isDedupKey))
+	err := b.Run()
+	assert.EqualError(t, err, "failed to initialize bot: storage cleanup interval must be positive")
+
+	b = joetest.NewBot(t, joe.WithStorageCleanup(time.Minute, nil))
+	err = b.Run()
+	assert.EqualError(t, err, "failed to initialize bot: storage cleanup predicate must not be nil")
+}
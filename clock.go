@@ -0,0 +1,53 @@
+package joe
+
+import "time"
+
+// A Clock provides the current time and timers. All time dependent features of
+// joe use the Clock of the bot so they can be tested without actually waiting.
+// By default the bot uses the real wall clock. Tests can inject a different
+// Clock via the WithClock(…) option (see joetest.Clock).
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// A Timer is a single event timer that was created by a Clock. Its semantics
+// are the same as the ones of the time.Timer of the standard library.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// WithClock is an option to replace the Clock of the bot which is mainly useful
+// in unit tests.
+func WithClock(c Clock) Module {
+	return ModuleFunc(func(conf *Config) error {
+		conf.clock = c
+		return nil
+	})
+}
+
+// realClock is the default Clock which uses the time package.
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
@@ -2,6 +2,7 @@ package joe
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"go.uber.org/zap"
@@ -12,16 +13,54 @@ import (
 // in a Module. Some configuration settings such as the Logger are read only can
 // only be accessed via the corresponding getter function of the Config.
 type Config struct {
-	Context        context.Context
-	Name           string
-	HandlerTimeout time.Duration
+	Context          context.Context
+	Name             string
+	HandlerTimeout   time.Duration
+	MaxMessageLength int    // used by Message.RespondPaged(…) to split long responses
+	PageNumbers      bool   // if true, Message.RespondPaged(…) adds "(page n/m)" to each page
+	SelfMessages     bool   // if true, messages authored by the bot itself are not ignored
+	IgnoreBots       bool   // if true, messages authored by other bots are ignored
+	MultiMatch       bool   // if true, all matching message handlers are executed
+	EventQueueLimit  int    // limits the events emitted via Brain.EmitBlocking(…), zero means unlimited
+	DefaultChannel   string // used by Bot.Broadcast(…)
 
-	logger   *zap.Logger
-	logLevel zapcore.Level
-	brain    *Brain
-	store    *Storage
-	adapter  Adapter
-	errs     []error
+	logger    *zap.Logger
+	logLevel  zapcore.Level
+	brain     *Brain
+	store     *Storage
+	adapter   Adapter
+	observers []Observer
+	tracer    Tracer
+	errPolicy HandlerErrorPolicy
+	authRead  ReadFallback
+	clock     Clock
+	errs      []error
+
+	localizer     Localizer
+	defaultLocale string
+
+	confirmYes, confirmNo []string
+
+	preprocessors []func(string) string
+
+	loopGuardWindow time.Duration
+	memoryCacheTTL  time.Duration
+	memoryCacheSize int
+
+	sendRetryAttempts int
+	sendRetryBackoff  time.Duration
+
+	commandToggles      bool
+	commandTogglesScope string
+
+	cooldownMessage string
+	auditLog        bool
+
+	storageCleanups []storageCleanup
+
+	commandErrorResponder func(Message, error) string
+
+	responders []func(*Bot) // registered via Config.Respond(…)
 }
 
 // NewConfig creates a new Config that is used to setup the underlying
@@ -37,6 +76,48 @@ func NewConfig(logger *zap.Logger, brain *Brain, store *Storage, adapter Adapter
 	}
 }
 
+// A ModuleValidator is an optional interface that Modules can implement to
+// validate the final Config after all Modules have been applied in joe.New(…).
+// Any returned error is returned on the next call to Bot.Run().
+type ModuleValidator interface {
+	Validate(*Config) error
+}
+
+// validate checks the invariants of the Config and runs the validation of all
+// modules that implement the ModuleValidator interface.
+func (c *Config) validate(modules []Module) []error {
+	var errs []error
+	if c.Name == "" {
+		errs = append(errs, errors.New("bot name must not be empty"))
+	}
+
+	if c.adapter == nil {
+		errs = append(errs, errors.New("bot has no adapter"))
+	}
+
+	if c.Context == nil {
+		errs = append(errs, errors.New("bot has no context"))
+	}
+
+	if c.HandlerTimeout < 0 {
+		errs = append(errs, errors.New("handler timeout must not be negative"))
+	}
+
+	if c.EventQueueLimit < 0 {
+		errs = append(errs, errors.New("event queue limit must not be negative"))
+	}
+
+	for _, mod := range modules {
+		if v, ok := mod.(ModuleValidator); ok {
+			if err := v.Validate(c); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errs
+}
+
 // The EventEmitter can be used by a Module by calling Config.EventEmitter().
 // Events are emitted asynchronously so every call to Emit is non-blocking.
 type EventEmitter interface {
@@ -65,14 +146,42 @@ func (c *Config) SetMemoryEncoder(enc MemoryEncoder) {
 	c.store.SetMemoryEncoder(enc)
 }
 
+// Adapter returns the Adapter that is currently configured. Modules can use
+// this function to wrap the current Adapter (e.g. to add logging or rate
+// limiting) and then pass the wrapper to Config.SetAdapter(…).
+func (c *Config) Adapter() Adapter {
+	return c.adapter
+}
+
+// Clock returns the Clock of the bot. Modules should use it for all time
+// dependent features instead of the time package so they can be tested via
+// the WithClock(…) option.
+func (c *Config) Clock() Clock {
+	if c.clock == nil {
+		return realClock{}
+	}
+
+	return c.clock
+}
+
 // SetAdapter can be used to change the Adapter implementation of the Bot.
 func (c *Config) SetAdapter(a Adapter) {
 	c.adapter = a
 }
 
 // RegisterHandler can be used to register an event handler in a Module.
-func (c *Config) RegisterHandler(fun interface{}) {
-	c.brain.RegisterHandler(fun)
+func (c *Config) RegisterHandler(fun interface{}, opts ...HandlerOption) {
+	c.brain.RegisterHandler(fun, opts...)
+}
+
+// Respond can be used to register a message handler in a Module. The handler is
+// registered via Bot.Respond(…) as soon as the Bot was created, so it behaves
+// exactly like all other commands of the bot (e.g. regarding ignored messages
+// or the WithCommandToggles(…) option).
+func (c *Config) Respond(msg string, fun func(Message) error) {
+	c.responders = append(c.responders, func(b *Bot) {
+		b.Respond(msg, fun)
+	})
 }
 
 // WithContext is an option to replace the default context of a bot.
@@ -92,6 +201,161 @@ func WithHandlerTimeout(timeout time.Duration) Module {
 	})
 }
 
+// WithHandlerErrorPolicy is an option to decide what happens when an event
+// handler returns an error. By default the error is logged and the remaining
+// handlers of the event are executed anyway (see ContinueOnError). Pass
+// AbortOnError or a custom HandlerErrorPolicy to stop executing the remaining
+// handlers instead.
+func WithHandlerErrorPolicy(policy HandlerErrorPolicy) Module {
+	return ModuleFunc(func(conf *Config) error {
+		if policy == nil {
+			return errors.New("handler error policy must not be nil")
+		}
+
+		conf.errPolicy = policy
+		return nil
+	})
+}
+
+// WithMaxMessageLength is an option to set the maximum length of a single
+// message that is sent via Message.RespondPaged(…). By default messages are
+// split after 4000 characters which is the limit recommended by Slack.
+func WithMaxMessageLength(n int) Module {
+	return ModuleFunc(func(conf *Config) error {
+		conf.MaxMessageLength = n
+		return nil
+	})
+}
+
+// WithPageNumbers is an option to let Message.RespondPaged(…) end each message
+// with a "(page n/m)" suffix if the response needs more than one message.
+func WithPageNumbers() Module {
+	return ModuleFunc(func(conf *Config) error {
+		conf.PageNumbers = true
+		return nil
+	})
+}
+
+// WithSelfMessages is an option to let the handlers that are registered via
+// Bot.Respond(…) and Bot.RespondRegex(…) also receive messages that have been
+// authored by the bot itself. By default these messages are ignored if the
+// Adapter implements the SelfAwareAdapter interface.
+func WithSelfMessages() Module {
+	return ModuleFunc(func(conf *Config) error {
+		conf.SelfMessages = true
+		return nil
+	})
+}
+
+// WithIgnoreBots is an option to let the handlers that are registered via
+// Bot.Respond(…) and Bot.RespondRegex(…) ignore all messages that have been
+// authored by other bots (see ReceiveMessageEvent.AuthorIsBot). This prevents
+// loops between bots that respond to each other.
+func WithIgnoreBots() Module {
+	return ModuleFunc(func(conf *Config) error {
+		conf.IgnoreBots = true
+		return nil
+	})
+}
+
+// WithInMemoryLimits is an option to replace the default in-memory Memory of
+// the bot with one that holds at most maxEntries keys and approximately
+// maxBytes of keys and values. If any of the limits is exceeded, the least
+// recently used entries are evicted. A limit of zero means no limit.
+func WithInMemoryLimits(maxEntries, maxBytes int) Module {
+	return ModuleFunc(func(conf *Config) error {
+		mem := newInMemory()
+		mem.maxEntries = maxEntries
+		mem.maxBytes = maxBytes
+		conf.SetMemory(mem)
+		return nil
+	})
+}
+
+// WithMultiMatch is an option to execute all message handlers that match a
+// received message instead of only the first one. By default, the first
+// matching handler that was registered via Bot.Respond(…) or
+// Bot.RespondRegex(…) wins and no other handlers are executed.
+func WithMultiMatch() Module {
+	return ModuleFunc(func(conf *Config) error {
+		conf.MultiMatch = true
+		return nil
+	})
+}
+
+// WithMessagePreprocessor is an option to normalize the text of all received
+// messages before it is matched against the patterns of the handlers that are
+// registered via Bot.Respond(…) and its variants (e.g. to trim whitespace or
+// to replace smart quotes, see NormalizeText). The Matches of the Message
+// are taken from the preprocessed text while the Message.Text and the
+// ReceiveMessageEvent.Text still contain the original text. If this option is
+// passed multiple times, all preprocessors are applied in the given order.
+func WithMessagePreprocessor(fun func(string) string) Module {
+	return ModuleFunc(func(conf *Config) error {
+		conf.preprocessors = append(conf.preprocessors, fun)
+		return nil
+	})
+}
+
+// WithEventQueueLimit is an option to limit the number of events that can be
+// queued via Brain.EmitBlocking(…). Adapters which use this function are
+// blocked when the limit is reached until the bot has processed enough events.
+// This protects the bot against running out of memory if an Adapter receives
+// more events than the bot can handle. Events that are emitted via
+// Brain.Emit(…) never block and do not count towards the limit. By default
+// there is no limit.
+func WithEventQueueLimit(n int) Module {
+	return ModuleFunc(func(conf *Config) error {
+		conf.EventQueueLimit = n
+		return nil
+	})
+}
+
+// WithDefaultChannel is an option to set the channel to which messages are
+// sent via Bot.Broadcast(…).
+func WithDefaultChannel(channel string) Module {
+	return ModuleFunc(func(conf *Config) error {
+		conf.DefaultChannel = channel
+		return nil
+	})
+}
+
+// WithCommandErrorMessage is an option to send the given text to the channel of
+// a message if the handler that was registered via Bot.Respond(…) or any of its
+// variants returns an error or panics. This way the user who triggered the
+// command knows that something went wrong. The error itself is only logged.
+func WithCommandErrorMessage(text string) Module {
+	return WithCommandErrorResponder(func(Message, error) string {
+		return text
+	})
+}
+
+// WithCommandErrorResponder is like WithCommandErrorMessage(…) but the given
+// function decides what the bot responds if a command fails. It receives the
+// Message that triggered the command and the error that was returned by the
+// handler (or the recovered panic) and returns the text to send to the channel
+// of the Message (e.g. a localized message). If it returns an empty string,
+// nothing is sent. By default the bot does not respond to failed commands.
+func WithCommandErrorResponder(fun func(Message, error) string) Module {
+	return ModuleFunc(func(conf *Config) error {
+		conf.commandErrorResponder = fun
+		return nil
+	})
+}
+
+// WithAuthFallback is an option to decide what Auth.CheckPermission(…) and
+// Auth.UserPermissions(…) do if the permissions cannot be read from the Memory
+// (e.g. because the redis server is down). By default the error is returned
+// (see FailOnError). Passing TreatAsMissing denies all permissions while the
+// Memory is not available and UseLastKnown uses the permissions that were read
+// most recently. Auth.Grant(…) and Auth.Revoke(…) never use a fallback.
+func WithAuthFallback(fallback ReadFallback) Module {
+	return ModuleFunc(func(conf *Config) error {
+		conf.authRead = fallback
+		return nil
+	})
+}
+
 // WithLogger is an option to replace the default logger of a bot.
 func WithLogger(logger *zap.Logger) Module {
 	return loggerModule(func(conf *Config) error {
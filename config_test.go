@@ -1,10 +1,13 @@
 package joe
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest"
 )
@@ -25,6 +28,7 @@ func TestConfig(t *testing.T) {
 	adapter := new(MockAdapter)
 	conf.SetAdapter(adapter)
 	assert.Equal(t, adapter, conf.adapter)
+	assert.Equal(t, adapter, conf.Adapter())
 
 	mem := newInMemory()
 	conf.SetMemory(mem)
@@ -53,6 +57,108 @@ func TestWithHandlerTimeout(t *testing.T) {
 	assert.Equal(t, 42*time.Millisecond, conf.HandlerTimeout)
 }
 
+func TestWithHandlerErrorPolicy(t *testing.T) {
+	var conf Config
+	mod := WithHandlerErrorPolicy(AbortOnError)
+	err := mod.Apply(&conf)
+	assert.NoError(t, err)
+	assert.Equal(t, AbortHandlers, conf.errPolicy(errors.New("test")))
+
+	err = WithHandlerErrorPolicy(nil).Apply(&conf)
+	assert.EqualError(t, err, "handler error policy must not be nil")
+}
+
+func TestWithMaxMessageLength(t *testing.T) {
+	var conf Config
+	mod := WithMaxMessageLength(100)
+	err := mod.Apply(&conf)
+	assert.NoError(t, err)
+	assert.Equal(t, 100, conf.MaxMessageLength)
+}
+
+func TestWithPageNumbers(t *testing.T) {
+	var conf Config
+	mod := WithPageNumbers()
+	err := mod.Apply(&conf)
+	assert.NoError(t, err)
+	assert.True(t, conf.PageNumbers)
+}
+
+func TestWithInMemoryLimits(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	store := NewStorage(logger)
+	conf := Config{store: store}
+
+	mod := WithInMemoryLimits(10, 100)
+	err := mod.Apply(&conf)
+	assert.NoError(t, err)
+
+	mem, ok := store.memory.(*inMemory)
+	if assert.True(t, ok) {
+		assert.Equal(t, 10, mem.maxEntries)
+		assert.Equal(t, 100, mem.maxBytes)
+	}
+}
+
+func TestWithIgnoreBots(t *testing.T) {
+	var conf Config
+	mod := WithIgnoreBots()
+	err := mod.Apply(&conf)
+	assert.NoError(t, err)
+	assert.True(t, conf.IgnoreBots)
+}
+
+func TestWithMultiMatch(t *testing.T) {
+	var conf Config
+	mod := WithMultiMatch()
+	err := mod.Apply(&conf)
+	assert.NoError(t, err)
+	assert.True(t, conf.MultiMatch)
+}
+
+func TestWithClock(t *testing.T) {
+	var conf Config
+	assert.Equal(t, realClock{}, conf.Clock(), "should use the real clock by default")
+
+	clock := new(instantClock)
+	mod := WithClock(clock)
+	err := mod.Apply(&conf)
+	assert.NoError(t, err)
+	assert.Equal(t, clock, conf.Clock())
+}
+
+func TestWithEventQueueLimit(t *testing.T) {
+	var conf Config
+	mod := WithEventQueueLimit(100)
+	err := mod.Apply(&conf)
+	assert.NoError(t, err)
+	assert.Equal(t, 100, conf.EventQueueLimit)
+}
+
+func TestWithMessagePreprocessor(t *testing.T) {
+	var conf Config
+	mod := WithMessagePreprocessor(NormalizeText)
+	err := mod.Apply(&conf)
+	assert.NoError(t, err)
+	assert.Len(t, conf.preprocessors, 1)
+}
+
+func TestWithDefaultChannel(t *testing.T) {
+	var conf Config
+	mod := WithDefaultChannel("general")
+	err := mod.Apply(&conf)
+	assert.NoError(t, err)
+	assert.Equal(t, "general", conf.DefaultChannel)
+}
+
+func TestWithAuthFallback(t *testing.T) {
+	var conf Config
+	mod := WithAuthFallback(TreatAsMissing)
+	err := mod.Apply(&conf)
+	assert.NoError(t, err)
+	assert.Equal(t, TreatAsMissing, conf.authRead)
+}
+
 func TestWithLogLevel(t *testing.T) {
 	mod := WithLogLevel(zap.ErrorLevel)
 
@@ -68,3 +174,16 @@ func TestWithLogLevel(t *testing.T) {
 func TestNewLogger(t *testing.T) {
 	newLogger(nil)
 }
+
+func TestNewContext(t *testing.T) {
+	type ctxKey string
+	ctx := context.WithValue(context.Background(), ctxKey("test"), true)
+
+	actual := newContext([]Module{WithLogger(zap.NewNop()), WithContext(ctx)})
+	assert.Equal(t, ctx, actual, "the context of WithContext(…) should be used without listening for signals")
+
+	sc, ok := newContext(nil).(signalContext)
+	require.True(t, ok, "the default context should listen for signals")
+	sc.stop()
+	assert.Error(t, sc.Err())
+}
@@ -0,0 +1,216 @@
+package joe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultAwaitTimeout is the maximum duration Message.Await(…) waits for a
+// reply if the context of the message has no earlier deadline.
+const DefaultAwaitTimeout = time.Minute
+
+// DefaultConfirmYes and DefaultConfirmNo are the replies that are accepted by Message.Confirm(…)
+// if no other words are configured via WithConfirmWords(…).
+var (
+	DefaultConfirmYes = []string{"yes", "y"}
+	DefaultConfirmNo  = []string{"no", "n"}
+)
+
+// WithConfirmWords is an option to configure which replies are accepted by
+// Message.Confirm(…) to confirm or to reject a prompt. Replies are compared
+// case insensitive.
+func WithConfirmWords(yes, no []string) Module {
+	return ModuleFunc(func(conf *Config) error {
+		if len(yes) == 0 || len(no) == 0 {
+			return errors.New("confirm words must not be empty")
+		}
+
+		conf.confirmYes = yes
+		conf.confirmNo = no
+		return nil
+	})
+}
+
+// conversationKey identifies a conversation with a user in a channel.
+type conversationKey struct {
+	channel string
+	userID  string
+}
+
+// conversations keeps track of all message handlers that wait for a reply via
+// Message.Await(…). Replies are intercepted when they are emitted to the Brain
+// because the Brain processes events sequentially and the waiting handler
+// would otherwise block the reply from ever being handled.
+type conversations struct {
+	mu      sync.Mutex
+	waiting map[conversationKey]chan string
+
+	clock   Clock
+	yes, no []string // accepted replies of Message.Confirm(…)
+}
+
+func newConversations(clock Clock, yes, no []string) *conversations {
+	if len(yes) == 0 {
+		yes = DefaultConfirmYes
+	}
+	if len(no) == 0 {
+		no = DefaultConfirmNo
+	}
+
+	return &conversations{
+		waiting: map[conversationKey]chan string{},
+		clock:   clock,
+		yes:     yes,
+		no:      no,
+	}
+}
+
+// start registers a new conversation and returns the channel on which the
+// reply will be delivered.
+func (c *conversations) start(key conversationKey) (chan string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.waiting[key]; ok {
+		return nil, ErrConversationInProgress
+	}
+
+	reply := make(chan string, 1)
+	c.waiting[key] = reply
+	return reply, nil
+}
+
+// end removes the conversation if it is still waiting for a reply.
+func (c *conversations) end(key conversationKey, reply chan string) {
+	c.mu.Lock()
+	if c.waiting[key] == reply {
+		delete(c.waiting, key)
+	}
+	c.mu.Unlock()
+}
+
+// intercept delivers the event to a waiting conversation if it is a reply. The
+// returned boolean is true if the event was consumed and should not be handled
+// any further.
+func (c *conversations) intercept(event interface{}) bool {
+	msg, ok := event.(ReceiveMessageEvent)
+	if !ok {
+		return false
+	}
+
+	key := conversationKey{channel: msg.Channel, userID: msg.AuthorID}
+
+	c.mu.Lock()
+	reply, ok := c.waiting[key]
+	delete(c.waiting, key)
+	c.mu.Unlock()
+
+	if ok {
+		reply <- msg.Text // never blocks since the channel is buffered
+	}
+
+	return ok
+}
+
+// Await sends the prompt to the channel the message originated from and then
+// blocks until the author of the message replies in the same channel. The text
+// of the reply is returned and the reply is not passed to any other message
+// handlers. Await returns an error if the context of the message is done or
+// there was no reply within the DefaultAwaitTimeout.
+//
+// While the handler waits for the reply, it does not block the bot. Instead,
+// the Brain continues to process all other events (e.g. the messages of other
+// users or another conversation) and the handler keeps running in the
+// background. Errors that the handler returns after it called Await are only
+// logged.
+//
+// Example:
+//   env, err := msg.Await("Which environment should I deploy to?")
+//   if err != nil {
+//       return err
+//   }
+func (msg *Message) Await(prompt string) (string, error) {
+	if msg.conversations == nil {
+		return "", ErrNotImplemented
+	}
+
+	ctx := msg.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	key := conversationKey{channel: msg.Channel, userID: msg.AuthorID}
+	reply, err := msg.conversations.start(key)
+	if err != nil {
+		return "", err
+	}
+	defer msg.conversations.end(key, reply)
+
+	if err := msg.adapter.Send(prompt, msg.Channel); err != nil {
+		return "", err
+	}
+
+	timer := msg.conversations.clock.NewTimer(DefaultAwaitTimeout)
+	defer timer.Stop()
+
+	detachHandler(ctx)
+
+	select {
+	case text := <-reply:
+		return text, nil
+	case <-timer.C():
+		return "", fmt.Errorf("no reply received: %w", context.DeadlineExceeded)
+	case <-ctx.Done():
+		return "", fmt.Errorf("no reply received: %w", ctx.Err())
+	}
+}
+
+// Confirm sends the prompt to the channel the message originated from and waits
+// until the author of the message either confirms or rejects it. By default
+// "yes" and "y" confirm while "no" and "n" reject the prompt but this can be
+// changed via WithConfirmWords(…). If the author replies with anything else,
+// the bot asks again. An error is returned if there was no reply in time (see
+// Message.Await(…)).
+//
+// Example:
+//   ok, err := msg.Confirm("Do you really want to delete everything?")
+//   if err != nil || !ok {
+//       return err
+//   }
+func (msg *Message) Confirm(prompt string) (bool, error) {
+	if msg.conversations == nil {
+		return false, ErrNotImplemented
+	}
+
+	yes, no := msg.conversations.yes, msg.conversations.no
+	for {
+		reply, err := msg.Await(prompt)
+		if err != nil {
+			return false, err
+		}
+
+		reply = strings.TrimSpace(reply)
+		switch {
+		case containsFold(yes, reply):
+			return true, nil
+		case containsFold(no, reply):
+			return false, nil
+		}
+
+		prompt = fmt.Sprintf("Please answer with %q or %q.", yes[0], no[0])
+	}
+}
+
+func containsFold(words []string, s string) bool {
+	for _, w := range words {
+		if strings.EqualFold(w, s) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,158 @@
+package joe
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessage_Await(t *testing.T) {
+	a := new(MockAdapter)
+	c := newConversations(realClock{}, nil, nil)
+	msg := Message{adapter: a, conversations: c, Channel: "test", AuthorID: "alice"}
+
+	a.On("Send", "Which environment?", "test").Return(nil).Run(func(mock.Arguments) {
+		// Other users and channels must not be intercepted.
+		assert.False(t, c.intercept(ReceiveMessageEvent{Text: "staging", Channel: "test", AuthorID: "bob"}))
+		assert.False(t, c.intercept(ReceiveMessageEvent{Text: "staging", Channel: "other", AuthorID: "alice"}))
+		assert.False(t, c.intercept(InitEvent{}))
+
+		assert.True(t, c.intercept(ReceiveMessageEvent{Text: "prod", Channel: "test", AuthorID: "alice"}))
+	})
+
+	reply, err := msg.Await("Which environment?")
+	require.NoError(t, err)
+	assert.Equal(t, "prod", reply)
+	assert.Empty(t, c.waiting, "conversation should be cleaned up")
+	a.AssertExpectations(t)
+}
+
+func TestMessage_Await_ContextDone(t *testing.T) {
+	a := new(MockAdapter)
+	c := newConversations(realClock{}, nil, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	msg := Message{Context: ctx, adapter: a, conversations: c, Channel: "test", AuthorID: "alice"}
+	a.On("Send", "Which environment?", "test").Return(nil)
+
+	_, err := msg.Await("Which environment?")
+	assert.EqualError(t, err, "no reply received: context deadline exceeded")
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Empty(t, c.waiting, "conversation should be cleaned up")
+	assert.False(t, c.intercept(ReceiveMessageEvent{Text: "prod", Channel: "test", AuthorID: "alice"}))
+}
+
+func TestMessage_Await_Errors(t *testing.T) {
+	a := new(MockAdapter)
+	msg := Message{adapter: a, Channel: "test", AuthorID: "alice"}
+
+	_, err := msg.Await("Which environment?")
+	assert.Equal(t, ErrNotImplemented, err)
+
+	c := newConversations(realClock{}, nil, nil)
+	msg.conversations = c
+	_, err = c.start(conversationKey{channel: "test", userID: "alice"})
+	require.NoError(t, err)
+
+	_, err = msg.Await("Which environment?")
+	assert.Equal(t, ErrConversationInProgress, err)
+
+	sendErr := errors.New("connection lost")
+	msg.AuthorID = "bob"
+	a.On("Send", "Which environment?", "test").Return(sendErr)
+	_, err = msg.Await("Which environment?")
+	assert.Equal(t, sendErr, err)
+	assert.Len(t, c.waiting, 1, "conversation of bob should be cleaned up")
+
+	a.AssertExpectations(t)
+}
+
+func TestMessage_Confirm(t *testing.T) {
+	cases := map[string]struct {
+		replies  []string
+		expected bool
+	}{
+		"yes":         {replies: []string{"yes"}, expected: true},
+		"y":           {replies: []string{" Y "}, expected: true},
+		"no":          {replies: []string{"NO"}, expected: false},
+		"invalid_yes": {replies: []string{"maybe", "y"}, expected: true},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			a := new(MockAdapter)
+			conv := newConversations(realClock{}, nil, nil)
+			msg := Message{adapter: a, conversations: conv, Channel: "test", AuthorID: "alice"}
+
+			replies := c.replies
+			reply := func(mock.Arguments) {
+				conv.intercept(ReceiveMessageEvent{Text: replies[0], Channel: "test", AuthorID: "alice"})
+				replies = replies[1:]
+			}
+
+			a.On("Send", "Are you sure?", "test").Return(nil).Run(reply).Once()
+			if len(c.replies) > 1 {
+				a.On("Send", `Please answer with "yes" or "no".`, "test").Return(nil).Run(reply)
+			}
+
+			ok, err := msg.Confirm("Are you sure?")
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, ok)
+			a.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMessage_Confirm_CustomWords(t *testing.T) {
+	a := new(MockAdapter)
+	conv := newConversations(realClock{}, []string{"ja"}, []string{"nein"})
+	msg := Message{adapter: a, conversations: conv, Channel: "test", AuthorID: "alice"}
+
+	a.On("Send", "Sicher?", "test").Return(nil).Run(func(mock.Arguments) {
+		conv.intercept(ReceiveMessageEvent{Text: "yes", Channel: "test", AuthorID: "alice"})
+	})
+	a.On("Send", `Please answer with "ja" or "nein".`, "test").Return(nil).Run(func(mock.Arguments) {
+		conv.intercept(ReceiveMessageEvent{Text: "Nein", Channel: "test", AuthorID: "alice"})
+	})
+
+	ok, err := msg.Confirm("Sicher?")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	a.AssertExpectations(t)
+}
+
+func TestMessage_Confirm_Errors(t *testing.T) {
+	a := new(MockAdapter)
+	msg := Message{adapter: a, Channel: "test", AuthorID: "alice"}
+
+	_, err := msg.Confirm("Are you sure?")
+	assert.Equal(t, ErrNotImplemented, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	msg.Context = ctx
+	msg.conversations = newConversations(realClock{}, nil, nil)
+	a.On("Send", "Are you sure?", "test").Return(nil)
+
+	ok, err := msg.Confirm("Are you sure?")
+	assert.EqualError(t, err, "no reply received: context canceled")
+	assert.False(t, ok)
+}
+
+func TestWithConfirmWords(t *testing.T) {
+	var conf Config
+	err := WithConfirmWords([]string{"ja"}, []string{"nein"}).Apply(&conf)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ja"}, conf.confirmYes)
+	assert.Equal(t, []string{"nein"}, conf.confirmNo)
+
+	err = WithConfirmWords(nil, []string{"nein"}).Apply(&conf)
+	assert.EqualError(t, err, "confirm words must not be empty")
+}
@@ -0,0 +1,84 @@
+package joe
+
+import (
+	"sync"
+	"time"
+)
+
+// WithCooldownMessage is an option to reply to users that trigger a command
+// that was registered via Bot.RespondWithCooldown(…) while they are still in
+// its cooldown. The format must contain a single "%s" verb which is replaced
+// with the remaining time (e.g. "Please wait %s before using this command
+// again"). By default such messages are silently ignored.
+func WithCooldownMessage(format string) Module {
+	return ModuleFunc(func(conf *Config) error {
+		conf.cooldownMessage = format
+		return nil
+	})
+}
+
+// RespondWithCooldown is like Bot.Respond(…) but each user can trigger the
+// handler at most once per cooldown. Messages that match the pattern while the
+// author is still in the cooldown are not passed to the handler or to any
+// other message handler. If the WithCooldownMessage(…) option is used, the bot
+// tells the user how long to wait instead.
+//
+// The cooldown is tracked separately for each call of this function and it is
+// only kept in-process, so it is reset when the bot restarts.
+func (b *Bot) RespondWithCooldown(cooldown time.Duration, msg string, fun func(Message) error) {
+	c := &cooldowns{
+		clock:    b.clock,
+		duration: cooldown,
+		last:     map[string]time.Time{},
+	}
+
+	b.Respond(msg, func(msg Message) error {
+		remaining := c.check(msg.AuthorID)
+		if remaining <= 0 {
+			return fun(msg)
+		}
+
+		if b.cooldownMessage == "" {
+			return nil
+		}
+
+		// We round up so we never tell the user to wait "0s".
+		remaining = (remaining + time.Second - 1).Truncate(time.Second)
+		return msg.RespondE(b.cooldownMessage, remaining)
+	})
+}
+
+// cooldowns tracks when each user triggered a command for the last time.
+type cooldowns struct {
+	clock    Clock
+	duration time.Duration
+
+	mu   sync.Mutex
+	last map[string]time.Time // by user ID
+}
+
+// check returns the remaining cooldown of the user. If it is zero or negative,
+// the user is allowed to trigger the command and its cooldown starts again.
+func (c *cooldowns) check(userID string) time.Duration {
+	now := c.clock.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if last, ok := c.last[userID]; ok {
+		if remaining := c.duration - now.Sub(last); remaining > 0 {
+			return remaining
+		}
+	}
+
+	// Remove all expired entries so the map does not grow with each user that
+	// ever used the command.
+	for id, last := range c.last {
+		if now.Sub(last) >= c.duration {
+			delete(c.last, id)
+		}
+	}
+
+	c.last[userID] = now
+	return 0
+}
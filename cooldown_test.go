@@ -0,0 +1,75 @@
+package joe_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-joe/joe"
+	"github.com/go-joe/joe/joetest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBot_RespondWithCooldown(t *testing.T) {
+	clock := joetest.NewClock(time.Now())
+	b := joetest.NewBot(t, joe.WithClock(clock))
+
+	var calls []string
+	b.RespondWithCooldown(time.Minute, "deploy", func(msg joe.Message) error {
+		calls = append(calls, msg.AuthorID)
+		return nil
+	})
+
+	var otherCalls int
+	b.Brain.RegisterHandler(func(joe.ReceiveMessageEvent) {
+		otherCalls++
+	})
+
+	b.Start()
+	defer b.Stop()
+
+	send := func(author string) {
+		b.EmitSync(joe.ReceiveMessageEvent{Text: "deploy", AuthorID: author})
+	}
+
+	send("alice")
+	send("alice")
+	send("bob")
+	assert.Equal(t, []string{"alice", "bob"}, calls, "the cooldown should be tracked per user")
+	assert.Equal(t, 0, otherCalls, "throttled messages should not be passed to other handlers")
+
+	clock.Advance(30 * time.Second)
+	send("alice")
+	assert.Equal(t, []string{"alice", "bob"}, calls)
+
+	clock.Advance(30 * time.Second)
+	send("alice")
+	assert.Equal(t, []string{"alice", "bob", "alice"}, calls)
+}
+
+func TestWithCooldownMessage(t *testing.T) {
+	clock := joetest.NewClock(time.Now())
+	b := joetest.NewBot(t,
+		joe.WithClock(clock),
+		joe.WithCooldownMessage("Please wait %s before using this command again"),
+	)
+
+	b.RespondWithCooldown(time.Minute, "deploy", func(msg joe.Message) error {
+		return msg.RespondE("deploying")
+	})
+
+	b.Start()
+	defer b.Stop()
+
+	send := func() string {
+		b.ReadOutput() // discard any previous output
+		b.EmitSync(joe.ReceiveMessageEvent{Text: "deploy", AuthorID: "alice"})
+		return strings.TrimSuffix(b.ReadOutput(), "\n")
+	}
+
+	assert.Equal(t, "deploying", send())
+	assert.Equal(t, "Please wait 1m0s before using this command again", send())
+
+	clock.Advance(29500 * time.Millisecond)
+	assert.Equal(t, "Please wait 31s before using this command again", send())
+}
@@ -14,3 +14,45 @@ func (err Error) Error() string {
 // not all Adapter implementations may support emoji reactions and trying to
 // attach a reaction to a message might return this error.
 const ErrNotImplemented = Error("not implemented")
+
+// ErrEmptyScope is returned by the Auth functions if they are called with an
+// empty permission scope.
+const ErrEmptyScope = Error("scope cannot be empty")
+
+// ErrMoreGeneralScope is returned by Auth.Revoke(…) if the user still has a
+// scope that contains the scope that should be revoked.
+const ErrMoreGeneralScope = Error("the user still has the more general scope")
+
+// ErrMemoryUnavailable is returned if the Memory of the bot is currently not
+// available (e.g. because the connection to redis was lost). Memory
+// implementations should wrap their errors with this error if they detect that
+// their backend is unreachable.
+const ErrMemoryUnavailable = Error("memory unavailable")
+
+// ErrConcurrentModification is returned by Storage.Update(…) if a value could
+// not be updated because it was modified concurrently too often.
+const ErrConcurrentModification = Error("concurrent modification")
+
+// ErrAdapterClosed is returned by the CLIAdapter if it is used after it was
+// closed.
+const ErrAdapterClosed = Error("adapter is closed")
+
+// ErrConversationInProgress is returned by Message.Await(…) if the bot is
+// already waiting for a reply of the same user in the same channel.
+const ErrConversationInProgress = Error("conversation already in progress")
+
+// ErrNoDefaultChannel is returned by Bot.Broadcast(…) if the bot was not
+// configured with a default channel via WithDefaultChannel(…).
+const ErrNoDefaultChannel = Error("no default channel configured")
+
+// ErrNotInThread is returned by Message.ThreadRoot() if the message was not
+// posted in a thread.
+const ErrNotInThread = Error("message is not part of a thread")
+
+// ErrBrainClosed is returned by Brain.Request(…) and Brain.EmitBlocking(…) if
+// the Brain is already shut down and does not accept new events anymore.
+const ErrBrainClosed = Error("brain is already closed")
+
+// ErrNilEvent is returned by Brain.Request(…) and Brain.EmitBlocking(…) if
+// they are called with a nil event.
+const ErrNilEvent = Error("event must not be nil")
@@ -1,12 +1,35 @@
 package joe
 
-// The InitEvent is the first event that is handled by the Brain after the Bot
-// is started via Bot.Run().
+// The ModulesReadyEvent is handled by Bot.Run() after all Modules have been
+// applied and before the Adapter is registered at the Brain. Modules can use
+// it to finish their setup if it depends on other Modules (e.g. an Adapter that
+// needs the final Storage of the bot). It is always handled before the
+// InitEvent and before any events that are emitted by the Adapter. Handlers
+// must be registered during the setup (e.g. via Config.RegisterHandler(…)).
+type ModulesReadyEvent struct{}
+
+// The InitEvent is the first event that is handled by the Brain when its event
+// loop starts after the Bot is started via Bot.Run(). Only the
+// ModulesReadyEvent is handled before it.
 type InitEvent struct{}
 
 // The ShutdownEvent is the last event that is handled by the Brain before it
-// stops handling any events after the bot context is done.
-type ShutdownEvent struct{}
+// stops handling any events after the bot context is done. The Reason tells
+// handlers why the bot is shutting down so they can clean up accordingly.
+type ShutdownEvent struct {
+	Reason ShutdownReason
+}
+
+// A ShutdownReason describes what triggered the shutdown of the bot.
+type ShutdownReason string
+
+// The possible reasons of a ShutdownEvent.
+const (
+	ShutdownSignal    ShutdownReason = "signal"    // the bot received SIGINT, SIGQUIT or SIGTERM
+	ShutdownContext   ShutdownReason = "context"   // the context of the bot was canceled
+	ShutdownDrain     ShutdownReason = "drain"     // the bot was stopped via Bot.Drain(…)
+	ShutdownRequested ShutdownReason = "requested" // Brain.Shutdown(…) was called directly
+)
 
 // The ReceiveMessageEvent is typically emitted by an Adapter when the Bot sees
 // a new message from the chat.
@@ -16,6 +39,20 @@ type ReceiveMessageEvent struct {
 	AuthorID string // A string identifying the author of the message on the adapter.
 	Channel  string // The channel over which the message was received.
 
+	// AuthorIsBot is true if the message was authored by another bot or
+	// integration. It is only set by Adapters that know this information.
+	AuthorIsBot bool
+
+	// ThreadID identifies the thread in which the message was posted within
+	// the Channel. It is empty if the message was not posted in a thread or if
+	// the Adapter does not support threads. See Message.ThreadRoot().
+	ThreadID string
+
+	// Attachments contains all files that were attached to the message. It is
+	// only populated by Adapters that support attachments. See Attachment.Open(…)
+	// to download their content.
+	Attachments []Attachment
+
 	// A message may optionally also contain additional information that was
 	// received by the Adapter (e.g. with the slack adapter this may be the
 	// *slack.MessageEvent. Each Adapter implementation should document if and
@@ -30,3 +67,26 @@ type UserTypingEvent struct {
 	User    User
 	Channel string
 }
+
+// The AdapterEvent is emitted via Brain.EmitAdapterEvent(…) by Adapters for
+// events of the chat that they do not map to any other event type (e.g. channel
+// topic changes). It is only emitted if the bot was configured with the
+// WithAdapterEvents() option. The Type and Raw fields are specific to each
+// Adapter and may change between versions of an Adapter, so handlers should
+// only rely on them if there is no other way.
+type AdapterEvent struct {
+	Type string      // the name of the event as it is used by the chat
+	Raw  interface{} // the event as it was received by the Adapter
+}
+
+// The MemoryUnavailableEvent may be emitted by a Memory implementation when it
+// lost the connection to its backend (e.g. to redis). Handlers can use this
+// event to degrade gracefully until the MemoryRecoveredEvent is emitted.
+type MemoryUnavailableEvent struct {
+	Err error // the error that indicated the connection loss
+}
+
+// The MemoryRecoveredEvent may be emitted by a Memory implementation when it
+// reconnected to its backend after it previously emitted a
+// MemoryUnavailableEvent.
+type MemoryRecoveredEvent struct{}
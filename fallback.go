@@ -0,0 +1,176 @@
+package joe
+
+import (
+	"container/list"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// lastKnownLimit is the maximum number of last known values that the Storage
+// remembers for the UseLastKnown fallback. If the limit is reached, the least
+// recently read values are forgotten first.
+const lastKnownLimit = 1000
+
+// A ReadFallback decides what Storage.GetWithFallback(…) returns if the Memory
+// fails to read a key (e.g. because the redis server is down). Since the best
+// behavior depends on how the value is used, the fallback is passed explicitly
+// at each call site instead of being configured for the whole Storage.
+type ReadFallback int
+
+// The available ReadFallback policies.
+const (
+	// FailOnError returns the error of the Memory. This is how Storage.Get(…)
+	// always behaves.
+	FailOnError ReadFallback = iota
+
+	// UseLastKnown returns the last value that was read successfully from
+	// the Memory via Storage.GetWithFallback(…). If there is no such value,
+	// the error of the Memory is returned.
+	UseLastKnown
+
+	// TreatAsMissing behaves as if the key does not exist (e.g. to deny all
+	// permissions while the Memory is not available).
+	TreatAsMissing
+)
+
+// String implements the fmt.Stringer interface.
+func (f ReadFallback) String() string {
+	switch f {
+	case FailOnError:
+		return "fail"
+	case UseLastKnown:
+		return "last-known"
+	case TreatAsMissing:
+		return "missing"
+	default:
+		return fmt.Sprintf("ReadFallback(%d)", int(f))
+	}
+}
+
+// lastKnownValue is the last value of a key that was read successfully via
+// Storage.GetWithFallback(…).
+type lastKnownValue struct {
+	key    string
+	data   []byte
+	exists bool
+}
+
+// GetWithFallback is like Storage.Get(…) but if the Memory returns an error,
+// the given fallback decides what is returned instead. This lets the bot
+// degrade gracefully if the Memory is temporarily not available. A warning is
+// logged each time a fallback is used.
+//
+// In order to support the UseLastKnown fallback, the Storage remembers the
+// encoded value of each key that is read successfully via this function. At
+// most 1000 values are remembered and the least recently read ones are
+// forgotten first. Whenever a key is changed or deleted via this Storage, its
+// last known value is forgotten so a fallback never returns a value that is
+// known to be outdated. Changes that are made to the Memory by other processes
+// however are only noticed with the next successful read.
+func (s *Storage) GetWithFallback(key string, value interface{}, fallback ReadFallback) (bool, error) {
+	gen := s.lastKnownGeneration()
+
+	s.mu.RLock()
+	s.logger.Debug("Retrieving data from memory", zap.String("key", key))
+	data, ok, err := s.memory.Get(key)
+	s.mu.RUnlock()
+
+	if err == nil && fallback == UseLastKnown {
+		s.rememberLastKnown(&lastKnownValue{key: key, data: data, exists: ok}, gen)
+	}
+
+	if err != nil {
+		switch fallback {
+		case UseLastKnown:
+			last, found := s.lookupLastKnown(key)
+			if !found {
+				return false, err
+			}
+			data, ok = last.data, last.exists
+		case TreatAsMissing:
+			data, ok = nil, false
+		default:
+			return false, err
+		}
+
+		s.logger.Warn("Failed to read from memory, using fallback",
+			zap.String("key", key),
+			zap.Stringer("fallback", fallback),
+			zap.Error(err),
+		)
+	}
+
+	if !ok || value == nil {
+		return ok, nil
+	}
+
+	err = s.encoder.Decode(data, value)
+	if err != nil {
+		return false, fmt.Errorf("decode data: %w", err)
+	}
+
+	return true, nil
+}
+
+// lastKnownGeneration returns the current write generation which must be
+// passed to Storage.rememberLastKnown(…) after reading from the Memory.
+func (s *Storage) lastKnownGeneration() uint64 {
+	s.lastKnownMu.Lock()
+	defer s.lastKnownMu.Unlock()
+	return s.lastKnownGen
+}
+
+// rememberLastKnown stores the value unless any key was modified since the
+// given generation, in which case the value we read may already be outdated.
+func (s *Storage) rememberLastKnown(value *lastKnownValue, gen uint64) {
+	s.lastKnownMu.Lock()
+	defer s.lastKnownMu.Unlock()
+
+	if s.lastKnownGen != gen {
+		return
+	}
+
+	if s.lastKnown == nil {
+		s.lastKnown = map[string]*list.Element{}
+		s.lastKnownLRU = list.New()
+	}
+
+	if elem, ok := s.lastKnown[value.key]; ok {
+		s.removeLastKnown(elem)
+	}
+
+	s.lastKnown[value.key] = s.lastKnownLRU.PushFront(value)
+	for s.lastKnownLRU.Len() > lastKnownLimit {
+		s.removeLastKnown(s.lastKnownLRU.Back())
+	}
+}
+
+func (s *Storage) lookupLastKnown(key string) (*lastKnownValue, bool) {
+	s.lastKnownMu.Lock()
+	defer s.lastKnownMu.Unlock()
+
+	elem, ok := s.lastKnown[key]
+	if !ok {
+		return nil, false
+	}
+
+	s.lastKnownLRU.MoveToFront(elem)
+	return elem.Value.(*lastKnownValue), true
+}
+
+// forgetLastKnown must be called whenever a key is modified via the Storage.
+func (s *Storage) forgetLastKnown(key string) {
+	s.lastKnownMu.Lock()
+	s.lastKnownGen++
+	if elem, ok := s.lastKnown[key]; ok {
+		s.removeLastKnown(elem)
+	}
+	s.lastKnownMu.Unlock()
+}
+
+// removeLastKnown deletes the given element. The caller must hold the lock.
+func (s *Storage) removeLastKnown(elem *list.Element) {
+	value := s.lastKnownLRU.Remove(elem).(*lastKnownValue)
+	delete(s.lastKnown, value.key)
+}
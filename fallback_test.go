@@ -0,0 +1,198 @@
+package joe
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// flakyMemory is a Memory whose backend can be made unreachable.
+type flakyMemory struct {
+	*inMemory
+	down     bool
+	afterGet func() // optional, called after each successful read
+}
+
+var errMemoryDown = errors.New("connection refused")
+
+func (m *flakyMemory) Get(key string) ([]byte, bool, error) {
+	if m.down {
+		return nil, false, errMemoryDown
+	}
+
+	data, ok, err := m.inMemory.Get(key)
+	if m.afterGet != nil {
+		m.afterGet()
+	}
+	return data, ok, err
+}
+
+func TestStorage_GetWithFallback(t *testing.T) {
+	cases := map[string]struct {
+		fallback ReadFallback
+		ok       bool
+		value    string
+		err      error
+	}{
+		"fail":       {fallback: FailOnError, err: errMemoryDown},
+		"last_known": {fallback: UseLastKnown, ok: true, value: "bar"},
+		"missing":    {fallback: TreatAsMissing, ok: false},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			mem := &flakyMemory{inMemory: newInMemory()}
+			store := NewStorage(zaptest.NewLogger(t))
+			store.SetMemory(mem)
+			require.NoError(t, store.Set("foo", "bar"))
+
+			var value string
+			ok, err := store.GetWithFallback("foo", &value, c.fallback)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			mem.down = true
+			value = ""
+			ok, err = store.GetWithFallback("foo", &value, c.fallback)
+			assert.Equal(t, c.err, err)
+			assert.Equal(t, c.ok, ok)
+			assert.Equal(t, c.value, value)
+		})
+	}
+}
+
+func TestStorage_GetWithFallback_LastKnownMissing(t *testing.T) {
+	mem := &flakyMemory{inMemory: newInMemory()}
+	store := NewStorage(zaptest.NewLogger(t))
+	store.SetMemory(mem)
+
+	ok, err := store.GetWithFallback("foo", nil, UseLastKnown)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// The key was known to be absent.
+	mem.down = true
+	ok, err = store.GetWithFallback("foo", nil, UseLastKnown)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// A key that was never read cannot fall back to anything.
+	ok, err = store.GetWithFallback("bar", nil, UseLastKnown)
+	assert.Equal(t, errMemoryDown, err)
+	assert.False(t, ok)
+}
+
+func TestStorage_GetWithFallback_Invalidation(t *testing.T) {
+	writes := map[string]func(*Storage) error{
+		"set": func(s *Storage) error {
+			return s.Set("foo", "baz")
+		},
+		"delete": func(s *Storage) error {
+			_, err := s.Delete("foo")
+			return err
+		},
+		"compare_and_swap": func(s *Storage) error {
+			_, err := s.CompareAndSwap("foo", "bar", "baz")
+			return err
+		},
+	}
+
+	for name, write := range writes {
+		t.Run(name, func(t *testing.T) {
+			mem := &flakyMemory{inMemory: newInMemory()}
+			store := NewStorage(zaptest.NewLogger(t))
+			store.SetMemory(mem)
+			require.NoError(t, store.Set("foo", "bar"))
+
+			ok, err := store.GetWithFallback("foo", nil, UseLastKnown)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			require.NoError(t, write(store))
+
+			// After the key was changed, the last known value is outdated
+			// and must not be used anymore.
+			mem.down = true
+			_, err = store.GetWithFallback("foo", nil, UseLastKnown)
+			assert.Equal(t, errMemoryDown, err)
+		})
+	}
+}
+
+func TestStorage_GetWithFallback_ConcurrentWrite(t *testing.T) {
+	mem := &flakyMemory{inMemory: newInMemory()}
+	store := NewStorage(zaptest.NewLogger(t))
+	store.SetMemory(mem)
+	require.NoError(t, store.Set("foo", "bar"))
+
+	// The key is changed after we read it but before the value is remembered.
+	mem.afterGet = func() {
+		mem.afterGet = nil
+		require.NoError(t, mem.inMemory.Set("foo", []byte(`"baz"`)))
+		store.forgetLastKnown("foo")
+	}
+
+	var value string
+	ok, err := store.GetWithFallback("foo", &value, UseLastKnown)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "bar", value)
+
+	mem.down = true
+	_, err = store.GetWithFallback("foo", &value, UseLastKnown)
+	assert.Equal(t, errMemoryDown, err, "the outdated value must not be remembered")
+}
+
+func TestStorage_GetWithFallback_Limit(t *testing.T) {
+	mem := &flakyMemory{inMemory: newInMemory()}
+	store := NewStorage(zaptest.NewLogger(t))
+	store.SetMemory(mem)
+
+	for i := 0; i <= lastKnownLimit; i++ {
+		_, err := store.GetWithFallback(fmt.Sprint("key", i), nil, UseLastKnown)
+		require.NoError(t, err)
+	}
+
+	assert.Len(t, store.lastKnown, lastKnownLimit)
+
+	mem.down = true
+	_, err := store.GetWithFallback("key0", nil, UseLastKnown)
+	assert.Equal(t, errMemoryDown, err, "the least recently read value should be forgotten")
+
+	_, err = store.GetWithFallback(fmt.Sprint("key", lastKnownLimit), nil, UseLastKnown)
+	assert.NoError(t, err)
+}
+
+func TestAuth_Fallback(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	mem := &flakyMemory{inMemory: newInMemory()}
+	store := NewStorage(logger)
+	store.SetMemory(mem)
+
+	auth := NewAuth(logger, store)
+	_, err := auth.Grant("test", "alice")
+	require.NoError(t, err)
+	require.NoError(t, auth.CheckPermission("test", "alice"))
+
+	mem.down = true
+	err = auth.CheckPermission("test", "alice")
+	assert.True(t, errors.Is(err, errMemoryDown))
+
+	auth.fallback = TreatAsMissing
+	err = auth.CheckPermission("test", "alice")
+	assert.Equal(t, ErrNotAllowed, err)
+
+	auth.fallback = UseLastKnown
+	mem.down = false
+	require.NoError(t, auth.CheckPermission("test", "alice"))
+	mem.down = true
+	assert.NoError(t, auth.CheckPermission("test", "alice"))
+
+	// Permissions must never be modified based on a fallback.
+	_, err = auth.Grant("other", "alice")
+	assert.True(t, errors.Is(err, errMemoryDown))
+}
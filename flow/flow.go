@@ -0,0 +1,241 @@
+// Package flow implements a small state machine for multi-step conversations
+// such as onboarding dialogs. The current state of each user is persisted in
+// the joe.Storage of the bot so a flow survives a restart of the bot.
+package flow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-joe/joe"
+)
+
+// Any can be used as key in State.Transitions to match any user input that is
+// not matched by another transition.
+const Any = "*"
+
+// A Flow is a state machine that guides a user through a conversation. Each
+// State has a prompt that is sent to the user when the state is entered and
+// transitions that map the reply of the user to the next state. A state
+// without any transitions is terminal and ends the flow.
+//
+// Example:
+//   onboarding := &flow.Flow{
+//       Name:    "onboarding",
+//       Initial: "team",
+//       States: map[string]flow.State{
+//           "team": {
+//               Prompt:      "Which team are you in?",
+//               Transitions: map[string]string{"backend": "oncall", flow.Any: "done"},
+//           },
+//           "oncall": {
+//               Prompt:      "Do you want to join the on-call rotation? (yes/no)",
+//               Transitions: map[string]string{"yes": "done", "no": "done"},
+//           },
+//           "done": {Prompt: "Welcome aboard!"},
+//       },
+//       Done: func(ctx context.Context, s flow.Session) error {
+//           // s.Answers contains the reply for each state, e.g. s.Answers["team"]
+//           return nil
+//       },
+//   }
+//
+//   onboarding.Register(b)
+//   b.Respond("onboard me", onboarding.Start)
+type Flow struct {
+	Name    string           // unique name of the flow, used as part of the storage key
+	Initial string           // the state in which each flow starts
+	States  map[string]State // all states of the flow by name
+
+	// Done is optional and called when a user reached a terminal state.
+	Done func(context.Context, Session) error
+
+	// Invalid is sent to the user if a reply does not match any transition of
+	// the current state. Afterwards the prompt of the state is sent again.
+	// If it is empty, only the prompt is repeated.
+	Invalid string
+
+	bot *joe.Bot
+}
+
+// A State of a Flow.
+type State struct {
+	Prompt      string            // sent to the user when entering the state
+	Transitions map[string]string // maps user input (case insensitive) to the next state
+}
+
+// A Session is the persisted progress of a single user in a Flow.
+type Session struct {
+	UserID  string            `json:"user_id"`
+	Channel string            `json:"channel"`
+	State   string            `json:"state"`
+	Answers map[string]string `json:"answers"` // the reply of the user to each state
+}
+
+// Register validates the flow and registers its message handler at the bot.
+// The handler runs before all handlers that were registered via
+// Bot.Respond(…) so that messages of users in the flow are not handled by
+// other commands.
+func (f *Flow) Register(bot *joe.Bot) error {
+	if err := f.validate(); err != nil {
+		return fmt.Errorf("invalid flow %q: %w", f.Name, err)
+	}
+
+	f.bot = bot
+	bot.Brain.RegisterHandler(f.handleMessage, joe.HandlerPriority(1))
+	return nil
+}
+
+func (f *Flow) validate() error {
+	if f.Name == "" {
+		return errors.New("name must not be empty")
+	}
+
+	if _, ok := f.States[f.Initial]; !ok {
+		return fmt.Errorf("initial state %q does not exist", f.Initial)
+	}
+
+	for name, state := range f.States {
+		for input, next := range state.Transitions {
+			if _, ok := f.States[next]; !ok {
+				return fmt.Errorf("transition %q of state %q leads to unknown state %q", input, name, next)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Start starts the flow for the author of the message in the channel of the
+// message. If the user was already in this flow, the flow starts over. Start
+// has the signature of a message handler so it can be passed directly to
+// Bot.Respond(…).
+func (f *Flow) Start(msg joe.Message) error {
+	if f.bot == nil {
+		return errors.New("flow is not registered")
+	}
+
+	s := Session{
+		UserID:  msg.AuthorID,
+		Channel: msg.Channel,
+		State:   f.Initial,
+		Answers: map[string]string{},
+	}
+
+	ctx := msg.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return f.enter(ctx, s)
+}
+
+// Cancel aborts the flow of the given user. The returned boolean is false if
+// the user was not in this flow.
+func (f *Flow) Cancel(userID string) (bool, error) {
+	if f.bot == nil {
+		return false, errors.New("flow is not registered")
+	}
+
+	return f.bot.Store.Delete(f.key(userID))
+}
+
+// Session returns the current session of the given user. The returned boolean
+// is false if the user is not in this flow.
+func (f *Flow) Session(userID string) (Session, bool, error) {
+	if f.bot == nil {
+		return Session{}, false, errors.New("flow is not registered")
+	}
+
+	var s Session
+	ok, err := f.bot.Store.Get(f.key(userID), &s)
+	return s, ok, err
+}
+
+func (f *Flow) handleMessage(ctx context.Context, evt joe.ReceiveMessageEvent) error {
+	s, ok, err := f.Session(evt.AuthorID)
+	if err != nil {
+		return fmt.Errorf("failed to load flow session: %w", err)
+	}
+
+	if !ok || s.Channel != evt.Channel {
+		return nil
+	}
+
+	// The message is a reply in the flow and must not be handled as a command.
+	joe.FinishEventContent(ctx)
+
+	input := strings.TrimSpace(evt.Text)
+	state := f.States[s.State]
+	next, ok := transition(state, input)
+	if !ok {
+		if f.Invalid != "" {
+			if err := f.send(f.Invalid, s.Channel); err != nil {
+				return err
+			}
+		}
+
+		return f.send(state.Prompt, s.Channel)
+	}
+
+	if s.Answers == nil {
+		s.Answers = map[string]string{}
+	}
+
+	s.Answers[s.State] = input
+	s.State = next
+
+	return f.enter(ctx, s)
+}
+
+// enter moves the session into its current state by sending the prompt and
+// either persisting the session or finishing the flow if the state is terminal.
+func (f *Flow) enter(ctx context.Context, s Session) error {
+	state := f.States[s.State]
+	if len(state.Transitions) > 0 {
+		if err := f.bot.Store.Set(f.key(s.UserID), s); err != nil {
+			return fmt.Errorf("failed to store flow session: %w", err)
+		}
+
+		return f.send(state.Prompt, s.Channel)
+	}
+
+	if _, err := f.bot.Store.Delete(f.key(s.UserID)); err != nil {
+		return fmt.Errorf("failed to delete flow session: %w", err)
+	}
+
+	if err := f.send(state.Prompt, s.Channel); err != nil {
+		return err
+	}
+
+	if f.Done == nil {
+		return nil
+	}
+
+	return f.Done(ctx, s)
+}
+
+func (f *Flow) send(text, channel string) error {
+	if text == "" {
+		return nil
+	}
+
+	return f.bot.Adapter.Send(text, channel)
+}
+
+func (f *Flow) key(userID string) string {
+	return "joe.flows." + f.Name + "." + userID
+}
+
+func transition(state State, input string) (string, bool) {
+	for in, next := range state.Transitions {
+		if in != Any && strings.EqualFold(in, input) {
+			return next, true
+		}
+	}
+
+	next, ok := state.Transitions[Any]
+	return next, ok
+}
@@ -0,0 +1,177 @@
+package flow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-joe/joe"
+	"github.com/go-joe/joe/joetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newOnboarding(done chan Session) *Flow {
+	return &Flow{
+		Name:    "onboarding",
+		Initial: "team",
+		Invalid: "Sorry, I did not get that.",
+		States: map[string]State{
+			"team": {
+				Prompt:      "Which team?",
+				Transitions: map[string]string{"backend": "oncall", Any: "done"},
+			},
+			"oncall": {
+				Prompt:      "On-call? (yes/no)",
+				Transitions: map[string]string{"yes": "done", "no": "done"},
+			},
+			"done": {Prompt: "Welcome!"},
+		},
+		Done: func(_ context.Context, s Session) error {
+			done <- s
+			return nil
+		},
+	}
+}
+
+func TestFlow(t *testing.T) {
+	b := joetest.NewBot(t)
+	done := make(chan Session, 1)
+	f := newOnboarding(done)
+	require.NoError(t, f.Register(b.Bot))
+
+	var commands []string
+	b.Respond("onboard me", f.Start)
+	b.Respond(".*", func(msg joe.Message) error {
+		commands = append(commands, msg.Text)
+		return nil
+	})
+
+	b.Start()
+	defer b.Stop()
+	assert.Equal(t, "test > ", b.ReadOutput())
+
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "onboard me", Channel: "general", AuthorID: "alice"})
+	assert.Equal(t, "Which team?\n", b.ReadOutput())
+
+	// Messages of other users and in other channels are not part of the flow.
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "backend", Channel: "general", AuthorID: "bob"})
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "backend", Channel: "random", AuthorID: "alice"})
+	assert.Equal(t, []string{"backend", "backend"}, commands)
+
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "Backend", Channel: "general", AuthorID: "alice"})
+	assert.Equal(t, "On-call? (yes/no)\n", b.ReadOutput())
+
+	s, ok, err := f.Session("alice")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "oncall", s.State)
+
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "maybe", Channel: "general", AuthorID: "alice"})
+	assert.Equal(t, "Sorry, I did not get that.\nOn-call? (yes/no)\n", b.ReadOutput())
+
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "yes", Channel: "general", AuthorID: "alice"})
+	assert.Equal(t, "Welcome!\n", b.ReadOutput())
+
+	select {
+	case s := <-done:
+		assert.Equal(t, Session{
+			UserID:  "alice",
+			Channel: "general",
+			State:   "done",
+			Answers: map[string]string{"team": "Backend", "oncall": "yes"},
+		}, s)
+	default:
+		t.Error("Done was not called")
+	}
+
+	_, ok, err = f.Session("alice")
+	require.NoError(t, err)
+	assert.False(t, ok, "session should be deleted when the flow is done")
+	assert.Equal(t, []string{"backend", "backend"}, commands)
+}
+
+func TestFlow_Resume(t *testing.T) {
+	b := joetest.NewBot(t)
+	done := make(chan Session, 1)
+
+	// The session is stored in the bot's memory so a new Flow with the same
+	// name continues where the user left off (e.g. after a restart).
+	err := b.Store.Set("joe.flows.onboarding.alice", Session{
+		UserID:  "alice",
+		Channel: "general",
+		State:   "team",
+	})
+	require.NoError(t, err)
+
+	f := newOnboarding(done)
+	require.NoError(t, f.Register(b.Bot))
+
+	b.Start()
+	defer b.Stop()
+	b.ReadOutput()
+
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "frontend", Channel: "general", AuthorID: "alice"})
+	assert.Equal(t, "Welcome!\n", b.ReadOutput())
+
+	s := <-done
+	assert.Equal(t, map[string]string{"team": "frontend"}, s.Answers)
+}
+
+func TestFlow_Cancel(t *testing.T) {
+	b := joetest.NewBot(t)
+	f := newOnboarding(make(chan Session, 1))
+	require.NoError(t, f.Register(b.Bot))
+
+	require.NoError(t, f.Start(joe.Message{AuthorID: "alice", Channel: "general"}))
+
+	ok, err := f.Cancel("alice")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = f.Cancel("alice")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestFlow_NotRegistered(t *testing.T) {
+	f := newOnboarding(nil)
+
+	err := f.Start(joe.Message{AuthorID: "alice"})
+	assert.EqualError(t, err, "flow is not registered")
+
+	_, err = f.Cancel("alice")
+	assert.EqualError(t, err, "flow is not registered")
+
+	_, _, err = f.Session("alice")
+	assert.EqualError(t, err, "flow is not registered")
+}
+
+func TestFlow_Register_Invalid(t *testing.T) {
+	cases := map[string]struct {
+		flow *Flow
+		err  string
+	}{
+		"no_name": {
+			flow: &Flow{},
+			err:  `invalid flow "": name must not be empty`,
+		},
+		"no_initial_state": {
+			flow: &Flow{Name: "test", Initial: "start"},
+			err:  `invalid flow "test": initial state "start" does not exist`,
+		},
+		"unknown_state": {
+			flow: &Flow{Name: "test", Initial: "start", States: map[string]State{
+				"start": {Transitions: map[string]string{"go": "nowhere"}},
+			}},
+			err: `invalid flow "test": transition "go" of state "start" leads to unknown state "nowhere"`,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			b := joetest.NewBot(t)
+			err := c.flow.Register(b.Bot)
+			assert.EqualError(t, err, c.err)
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package joe
+
+import (
+	"errors"
+	"fmt"
+)
+
+// localeKeyPrefix is the key prefix in the Storage that all user locales have.
+const localeKeyPrefix = "joe.locales."
+
+// A Localizer translates message keys into the language of a given locale
+// (e.g. "en" or "de-DE"). The returned boolean must be false if there is no
+// translation for the given key and locale.
+type Localizer interface {
+	Localize(locale, key string, args ...interface{}) (string, bool)
+}
+
+// MapLocalizer is a simple Localizer which maps locales to translations which
+// in turn map message keys to format strings. If any arguments are passed to
+// the Localize function, the translation is formatted via fmt.Sprintf.
+//
+// Example:
+//   joe.MapLocalizer{
+//       "en": {"greeting": "Hello %s"},
+//       "de": {"greeting": "Hallo %s"},
+//   }
+type MapLocalizer map[string]map[string]string
+
+// Localize implements the Localizer interface.
+func (l MapLocalizer) Localize(locale, key string, args ...interface{}) (string, bool) {
+	text, ok := l[locale][key]
+	if !ok {
+		return "", false
+	}
+
+	if len(args) > 0 {
+		text = fmt.Sprintf(text, args...)
+	}
+
+	return text, true
+}
+
+// WithLocalizer is an option to set the Localizer that is used by
+// Message.RespondLocalized(…). The default locale is used for all users that
+// have no locale set via Storage.SetUserLocale(…) and for all keys that have no
+// translation in the locale of a user.
+func WithLocalizer(l Localizer, defaultLocale string) Module {
+	return ModuleFunc(func(conf *Config) error {
+		conf.localizer = l
+		conf.defaultLocale = defaultLocale
+		return nil
+	})
+}
+
+// SetUserLocale stores the locale of the given user.
+func (s *Storage) SetUserLocale(userID, locale string) error {
+	return s.Set(localeKeyPrefix+userID, locale)
+}
+
+// UserLocale returns the locale of the given user. The boolean return value is
+// false if no locale was set for the user.
+func (s *Storage) UserLocale(userID string) (string, bool, error) {
+	var locale string
+	ok, err := s.Get(localeKeyPrefix+userID, &locale)
+	return locale, ok, err
+}
+
+// RespondLocalized sends the translation of the given key back to the channel
+// the message originated from. The translation is looked up in the locale of
+// the author of the message (see Storage.SetUserLocale(…)) and then in the
+// default locale that was passed to WithLocalizer(…). If there is no
+// translation at all, an error is returned.
+func (msg *Message) RespondLocalized(key string, args ...interface{}) error {
+	l := msg.localizer
+	if l.localizer == nil {
+		return errors.New("no localizer configured")
+	}
+
+	locale := l.defaultLocale
+	if l.store != nil && msg.AuthorID != "" {
+		userLocale, ok, err := l.store.UserLocale(msg.AuthorID)
+		if err != nil {
+			return fmt.Errorf("failed to load user locale: %w", err)
+		}
+		if ok {
+			locale = userLocale
+		}
+	}
+
+	text, ok := l.localizer.Localize(locale, key, args...)
+	if !ok && locale != l.defaultLocale {
+		text, ok = l.localizer.Localize(l.defaultLocale, key, args...)
+	}
+
+	if !ok {
+		return fmt.Errorf("missing translation for key %q", key)
+	}
+
+	return msg.adapter.Send(text, msg.Channel)
+}
+
+// messageLocalizer contains everything a Message needs to localize responses.
+type messageLocalizer struct {
+	localizer     Localizer
+	defaultLocale string
+	store         *Storage
+}
@@ -0,0 +1,95 @@
+package joe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestMapLocalizer(t *testing.T) {
+	l := MapLocalizer{
+		"en": {"greeting": "Hello %s", "bye": "Goodbye"},
+		"de": {"greeting": "Hallo %s"},
+	}
+
+	text, ok := l.Localize("de", "greeting", "Joe")
+	assert.True(t, ok)
+	assert.Equal(t, "Hallo Joe", text)
+
+	text, ok = l.Localize("en", "bye")
+	assert.True(t, ok)
+	assert.Equal(t, "Goodbye", text)
+
+	_, ok = l.Localize("de", "bye")
+	assert.False(t, ok)
+
+	_, ok = l.Localize("fr", "greeting")
+	assert.False(t, ok)
+}
+
+func TestStorage_UserLocale(t *testing.T) {
+	store := NewStorage(zaptest.NewLogger(t))
+
+	_, ok, err := store.UserLocale("alice")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, store.SetUserLocale("alice", "de"))
+
+	locale, ok, err := store.UserLocale("alice")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "de", locale)
+}
+
+func TestMessage_RespondLocalized(t *testing.T) {
+	a := new(MockAdapter)
+	store := NewStorage(zaptest.NewLogger(t))
+	require.NoError(t, store.SetUserLocale("alice", "de"))
+
+	l := messageLocalizer{
+		localizer: MapLocalizer{
+			"en": {"greeting": "Hello %s", "bye": "Goodbye"},
+			"de": {"greeting": "Hallo %s"},
+		},
+		defaultLocale: "en",
+		store:         store,
+	}
+
+	alice := Message{adapter: a, localizer: l, Channel: "test", AuthorID: "alice"}
+	bob := Message{adapter: a, localizer: l, Channel: "test", AuthorID: "bob"}
+
+	a.On("Send", "Hallo Alice", "test").Return(nil)
+	a.On("Send", "Goodbye", "test").Return(nil)
+	a.On("Send", "Hello Bob", "test").Return(nil)
+
+	assert.NoError(t, alice.RespondLocalized("greeting", "Alice"))
+	assert.NoError(t, alice.RespondLocalized("bye"), "should fall back to default locale")
+	assert.NoError(t, bob.RespondLocalized("greeting", "Bob"), "should use default locale")
+
+	err := bob.RespondLocalized("unknown")
+	assert.EqualError(t, err, `missing translation for key "unknown"`)
+
+	a.AssertExpectations(t)
+}
+
+func TestMessage_RespondLocalized_NoLocalizer(t *testing.T) {
+	a := new(MockAdapter)
+	msg := Message{adapter: a, Channel: "test"}
+
+	err := msg.RespondLocalized("greeting")
+	assert.EqualError(t, err, "no localizer configured")
+	a.AssertExpectations(t)
+}
+
+func TestWithLocalizer(t *testing.T) {
+	var conf Config
+	l := MapLocalizer{}
+	mod := WithLocalizer(l, "en")
+	err := mod.Apply(&conf)
+	assert.NoError(t, err)
+	assert.Equal(t, l, conf.localizer)
+	assert.Equal(t, "en", conf.defaultLocale)
+}
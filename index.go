@@ -0,0 +1,235 @@
+package joe
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	// indexKeyPrefix is the key prefix in the Storage under which the members
+	// of each index value are stored (i.e. "joe.indexes.<index>.<value>").
+	// Any "." and "\" in the index name are escaped with a backslash so the
+	// first unescaped "." always separates the index from the value.
+	indexKeyPrefix = "joe.indexes."
+
+	// indexedKeyPrefix is the key prefix in the Storage under which the index
+	// values of each indexed key are stored (i.e. "joe.indexed.<key>"). This
+	// is used to update the indexes if the key is indexed again or deleted.
+	indexedKeyPrefix = "joe.indexed."
+
+	// indexUsedKey is set when the first key is indexed so Storage.Delete(…)
+	// does not need to look up the index values of each deleted key if
+	// indexes are not used at all. It cannot collide with an index key since
+	// it does not contain an unescaped "." after the prefix.
+	indexUsedKey = indexKeyPrefix + "used"
+)
+
+// The states of Storage.indexState.
+const (
+	indexStateUnknown int32 = iota // the memory was not checked yet
+	indexStateUnused               // no key was indexed so far
+	indexStateUsed                 // at least one key was indexed
+)
+
+// keySegmentEscaper escapes the "." separator in user provided parts of
+// internal keys (e.g. index names or user IDs) so one part can never be
+// mistaken for the prefix of another.
+var keySegmentEscaper = strings.NewReplacer(`\`, `\\`, ".", `\.`)
+
+// Index adds the given key to a secondary index so it can be looked up via
+// Storage.IndexMembers(…). For instance, a bot that stores reminders under
+// keys like "reminders.<id>" can index each reminder by its user:
+//
+//	err := store.Index("reminders-by-user", "reminders.42", userID)
+//	…
+//	keys, err := store.IndexMembers("reminders-by-user", userID)
+//
+// A key has at most one value per index. If the key is indexed again with a
+// different value it is removed from the old value. When the key is deleted
+// via Storage.Delete(…) it is also removed from all indexes. Note that the
+// index is updated in multiple steps so it is not updated atomically.
+func (s *Storage) Index(index, key, value string) error {
+	var (
+		values   map[string]string // index name -> value
+		oldValue string
+		indexed  bool
+	)
+
+	err := s.markIndexUsed()
+	if err != nil {
+		return err
+	}
+
+	err = s.Update(indexedKeyPrefix+key, &values, func() error {
+		if values == nil {
+			values = map[string]string{}
+		}
+
+		oldValue, indexed = values[index]
+		values[index] = value
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update index of key: %w", err)
+	}
+
+	if indexed && oldValue != value {
+		err = s.removeIndexMember(index, oldValue, key)
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.addIndexMember(index, value, key)
+}
+
+// Unindex removes the given key from the index. The boolean return value
+// indicates if the key was part of the index.
+func (s *Storage) Unindex(index, key string) (bool, error) {
+	var (
+		values  map[string]string
+		value   string
+		indexed bool
+	)
+
+	err := s.Update(indexedKeyPrefix+key, &values, func() error {
+		value, indexed = values[index]
+		delete(values, index)
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to update index of key: %w", err)
+	}
+
+	if !indexed {
+		return false, nil
+	}
+
+	return true, s.removeIndexMember(index, value, key)
+}
+
+// IndexMembers returns all keys in alphabetical order that have been added to
+// the index with the given value via Storage.Index(…).
+func (s *Storage) IndexMembers(index, value string) ([]string, error) {
+	var keys []string
+	_, err := s.Get(indexKey(index, value), &keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get index members: %w", err)
+	}
+
+	return keys, nil
+}
+
+// markIndexUsed records that indexes are used so Storage.unindexAll(…) looks
+// up the indexes of deleted keys.
+func (s *Storage) markIndexUsed() error {
+	if atomic.LoadInt32(&s.indexState) == indexStateUsed {
+		return nil
+	}
+
+	err := s.Set(indexUsedKey, true)
+	if err != nil {
+		return fmt.Errorf("failed to mark indexes as used: %w", err)
+	}
+
+	atomic.StoreInt32(&s.indexState, indexStateUsed)
+	return nil
+}
+
+// indexUsed returns true if any key was indexed in the Memory. The Memory is
+// only checked once since the result only changes via Storage.Index(…).
+func (s *Storage) indexUsed(ctx context.Context) (bool, error) {
+	switch atomic.LoadInt32(&s.indexState) {
+	case indexStateUsed:
+		return true, nil
+	case indexStateUnused:
+		return false, nil
+	}
+
+	var marker bool
+	ok, err := s.GetCtx(ctx, indexUsedKey, &marker)
+	if err != nil {
+		return false, err
+	}
+
+	state := indexStateUnused
+	if ok {
+		state = indexStateUsed
+	}
+
+	// If a key was indexed concurrently, the state is already set.
+	atomic.CompareAndSwapInt32(&s.indexState, indexStateUnknown, state)
+	return ok, nil
+}
+
+// unindexAll removes the given key from all indexes it has been added to.
+func (s *Storage) unindexAll(ctx context.Context, key string) error {
+	used, err := s.indexUsed(ctx)
+	if err != nil || !used {
+		return err
+	}
+
+	var values map[string]string
+	ok, err := s.GetCtx(ctx, indexedKeyPrefix+key, &values)
+	if err != nil || !ok {
+		return err
+	}
+
+	for index, value := range values {
+		err = s.removeIndexMember(index, value, key)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = s.delete(ctx, indexedKeyPrefix+key)
+	return err
+}
+
+func (s *Storage) addIndexMember(index, value, key string) error {
+	var keys []string
+	err := s.Update(indexKey(index, value), &keys, func() error {
+		i := sort.SearchStrings(keys, key)
+		if i < len(keys) && keys[i] == key {
+			return nil // already indexed
+		}
+
+		keys = append(keys, "")
+		copy(keys[i+1:], keys[i:])
+		keys[i] = key
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add key to index: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Storage) removeIndexMember(index, value, key string) error {
+	var keys []string
+	err := s.Update(indexKey(index, value), &keys, func() error {
+		i := sort.SearchStrings(keys, key)
+		if i < len(keys) && keys[i] == key {
+			keys = append(keys[:i], keys[i+1:]...)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove key from index: %w", err)
+	}
+
+	return nil
+}
+
+func indexKey(index, value string) string {
+	return indexKeyPrefix + keySegmentEscaper.Replace(index) + "." + value
+}
+
+// isIndexKey returns true if the key is used internally to store the indexes.
+func isIndexKey(key string) bool {
+	return strings.HasPrefix(key, indexKeyPrefix) || strings.HasPrefix(key, indexedKeyPrefix)
+}
@@ -0,0 +1,111 @@
+package joe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestStorage_Index(t *testing.T) {
+	store := NewStorage(zaptest.NewLogger(t))
+
+	require.NoError(t, store.Set("reminders.1", "buy milk"))
+	require.NoError(t, store.Set("reminders.2", "call mom"))
+	require.NoError(t, store.Set("reminders.3", "deploy"))
+
+	require.NoError(t, store.Index("by-user", "reminders.2", "alice"))
+	require.NoError(t, store.Index("by-user", "reminders.1", "alice"))
+	require.NoError(t, store.Index("by-user", "reminders.1", "alice")) // idempotent
+	require.NoError(t, store.Index("by-user", "reminders.3", "bob"))
+	require.NoError(t, store.Index("by-channel", "reminders.3", "general"))
+
+	assertIndexMembers(t, store, "by-user", "alice", "reminders.1", "reminders.2")
+	assertIndexMembers(t, store, "by-user", "bob", "reminders.3")
+	assertIndexMembers(t, store, "by-channel", "general", "reminders.3")
+	assertIndexMembers(t, store, "by-user", "carol")
+
+	// Indexing a key with another value moves it.
+	require.NoError(t, store.Index("by-user", "reminders.2", "bob"))
+	assertIndexMembers(t, store, "by-user", "alice", "reminders.1")
+	assertIndexMembers(t, store, "by-user", "bob", "reminders.2", "reminders.3")
+
+	ok, err := store.Unindex("by-user", "reminders.1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assertIndexMembers(t, store, "by-user", "alice")
+
+	ok, err = store.Unindex("by-user", "reminders.1")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestStorage_Index_Delete(t *testing.T) {
+	store := NewStorage(zaptest.NewLogger(t))
+
+	require.NoError(t, store.Set("reminders.1", "buy milk"))
+	require.NoError(t, store.Set("reminders.2", "call mom"))
+	require.NoError(t, store.Index("by-user", "reminders.1", "alice"))
+	require.NoError(t, store.Index("by-user", "reminders.2", "alice"))
+	require.NoError(t, store.Index("by-channel", "reminders.1", "general"))
+
+	ok, err := store.Delete("reminders.1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	assertIndexMembers(t, store, "by-user", "alice", "reminders.2")
+	assertIndexMembers(t, store, "by-channel", "general")
+
+	ok, err = store.Has(indexedKeyPrefix + "reminders.1")
+	require.NoError(t, err)
+	assert.False(t, ok, "index values of deleted key should be removed")
+}
+
+func TestStorage_Index_Separator(t *testing.T) {
+	store := NewStorage(zaptest.NewLogger(t))
+
+	require.NoError(t, store.Index("a.b", "key1", "c"))
+	require.NoError(t, store.Index("a", "key2", "b.c"))
+	require.NoError(t, store.Index(`a\`, "key3", "b.c"))
+
+	assertIndexMembers(t, store, "a.b", "c", "key1")
+	assertIndexMembers(t, store, "a", "b.c", "key2")
+	assertIndexMembers(t, store, `a\`, "b.c", "key3")
+}
+
+func TestStorage_Delete_IndexNotUsed(t *testing.T) {
+	mem := &countingMemory{inMemory: newInMemory()}
+	store := NewStorage(zaptest.NewLogger(t))
+	store.SetMemory(mem)
+
+	require.NoError(t, store.Set("foo", "bar"))
+	for i := 0; i < 3; i++ {
+		_, err := store.Delete("foo")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, mem.gets, "only the first deletion should check if indexes are used")
+
+	// Another Storage that uses the same Memory must still update the indexes.
+	require.NoError(t, store.Set("foo", "bar"))
+	require.NoError(t, store.Index("by-user", "foo", "alice"))
+
+	other := NewStorage(zaptest.NewLogger(t))
+	other.SetMemory(mem)
+	_, err := other.Delete("foo")
+	require.NoError(t, err)
+	assertIndexMembers(t, other, "by-user", "alice")
+}
+
+func assertIndexMembers(t *testing.T, store *Storage, index, value string, expected ...string) {
+	t.Helper()
+
+	keys, err := store.IndexMembers(index, value)
+	require.NoError(t, err)
+
+	if len(expected) == 0 {
+		assert.Empty(t, keys)
+	} else {
+		assert.Equal(t, expected, keys)
+	}
+}
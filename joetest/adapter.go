@@ -0,0 +1,311 @@
+package joetest
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/go-joe/joe"
+	"github.com/go-joe/joe/reactions"
+)
+
+// Adapter is a joe.Adapter for unit tests. It records all messages and
+// reactions that are sent by the bot so they can be asserted later on and it
+// lets tests inject incoming messages via Adapter.Receive(…). Next to the
+// joe.Adapter interface it also implements all optional adapter interfaces
+// (i.e. joe.ReactionAwareAdapter, joe.ReactByIDAdapter, joe.EphemeralAdapter,
+// joe.SelfAwareAdapter, joe.EditingAdapter, joe.ThreadReaderAdapter,
+// joe.PresenceAdapter, joe.ChannelLister, joe.MemberLister, joe.CommandSyncer
+// and joe.Drainer). The data that is returned by the lookup interfaces can be
+// set via the exported fields before the bot is started.
+//
+// The Adapter is also a joe.Module so it can directly be passed to NewBot(…):
+//   a := joetest.NewAdapter()
+//   b := joetest.NewBot(t, a)
+type Adapter struct {
+	UserID string // returned by Adapter.BotUserID()
+	Err    error  // if set, all calls to send messages or reactions return this error
+
+	ThreadRoots    map[string]joe.Message  // returned by Adapter.ThreadRoot(…), by thread ID
+	Presences      map[string]joe.Presence // returned by Adapter.Presence(…), by user ID
+	ChannelList    []joe.Channel           // returned by Adapter.Channels()
+	ChannelMembers map[string][]joe.User   // returned by Adapter.Members(…), by channel ID
+
+	mu        sync.Mutex
+	brain     *joe.Brain
+	messages  []SentMessage
+	edits     []SentEdit
+	reactions []SentReaction
+	commands  []joe.CommandInfo
+	drained   bool
+	closed    bool
+}
+
+// SentMessage is a message that was sent via the Adapter.
+type SentMessage struct {
+	Text    string
+	Channel string
+	UserID  string // only set for ephemeral messages
+	ID      string // only set for messages sent via Adapter.SendMessage(…)
+}
+
+// SentEdit is an edit of a message that was sent via the Adapter.
+type SentEdit struct {
+	Channel   string
+	MessageID string
+	Text      string
+}
+
+// SentReaction is a reaction that was sent via the Adapter.
+type SentReaction struct {
+	Reaction reactions.Reaction
+	Message  joe.Message
+}
+
+// NewAdapter creates a new Adapter for unit tests.
+func NewAdapter() *Adapter {
+	return &Adapter{}
+}
+
+// Apply implements the joe.Module interface by setting the Adapter as the
+// adapter of the bot.
+func (a *Adapter) Apply(conf *joe.Config) error {
+	conf.SetAdapter(a)
+	return nil
+}
+
+// RegisterAt implements the joe.Adapter interface.
+func (a *Adapter) RegisterAt(brain *joe.Brain) {
+	a.mu.Lock()
+	a.brain = brain
+	a.mu.Unlock()
+}
+
+// Receive emits a joe.ReceiveMessageEvent as if it was received from the chat.
+// The Adapter must be registered at a Brain before this function is called.
+// After the Adapter was drained, the event is discarded.
+func (a *Adapter) Receive(evt joe.ReceiveMessageEvent) {
+	a.mu.Lock()
+	brain, drained := a.brain, a.drained
+	a.mu.Unlock()
+
+	if brain == nil {
+		panic("joetest.Adapter: Receive called before the adapter was registered at a brain")
+	}
+
+	if drained {
+		return
+	}
+
+	brain.Emit(evt)
+}
+
+// Send implements the joe.Adapter interface by recording the message.
+func (a *Adapter) Send(text, channel string) error {
+	return a.record(SentMessage{Text: text, Channel: channel})
+}
+
+// SendEphemeral implements the joe.EphemeralAdapter interface by recording the
+// message.
+func (a *Adapter) SendEphemeral(channel, userID, text string) error {
+	return a.record(SentMessage{Text: text, Channel: channel, UserID: userID})
+}
+
+// SendMessage implements the joe.EditingAdapter interface by recording the
+// message. The returned ID is the number of messages that have been sent so far.
+func (a *Adapter) SendMessage(text, channel string) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.Err != nil {
+		return "", a.Err
+	}
+
+	id := strconv.Itoa(len(a.messages) + 1)
+	a.messages = append(a.messages, SentMessage{Text: text, Channel: channel, ID: id})
+	return id, nil
+}
+
+// Edit implements the joe.EditingAdapter interface by replacing the text of the
+// recorded message and recording the edit itself. Only messages that have been
+// sent via Adapter.SendMessage(…) can be edited.
+func (a *Adapter) Edit(channel, messageID, text string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.Err != nil {
+		return a.Err
+	}
+
+	for i, msg := range a.messages {
+		if msg.ID != "" && msg.ID == messageID && msg.Channel == channel {
+			a.messages[i].Text = text
+			a.edits = append(a.edits, SentEdit{Channel: channel, MessageID: messageID, Text: text})
+			return nil
+		}
+	}
+
+	return fmt.Errorf("message %q not found in channel %q", messageID, channel)
+}
+
+func (a *Adapter) record(msg SentMessage) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.Err != nil {
+		return a.Err
+	}
+
+	a.messages = append(a.messages, msg)
+	return nil
+}
+
+// React implements the joe.ReactionAwareAdapter interface by recording the
+// reaction.
+func (a *Adapter) React(r reactions.Reaction, msg joe.Message) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.Err != nil {
+		return a.Err
+	}
+
+	a.reactions = append(a.reactions, SentReaction{Reaction: r, Message: msg})
+	return nil
+}
+
+// ReactID implements the joe.ReactByIDAdapter interface. The reaction is
+// recorded with a joe.Message that only has the given ID and Channel.
+func (a *Adapter) ReactID(r reactions.Reaction, channel, messageID string) error {
+	return a.React(r, joe.Message{ID: messageID, Channel: channel})
+}
+
+// BotUserID implements the joe.SelfAwareAdapter interface.
+func (a *Adapter) BotUserID() string {
+	return a.UserID
+}
+
+// ThreadRoot implements the joe.ThreadReaderAdapter interface by looking up
+// the thread in Adapter.ThreadRoots.
+func (a *Adapter) ThreadRoot(channel, threadID string) (joe.Message, error) {
+	msg, ok := a.ThreadRoots[threadID]
+	if !ok {
+		return joe.Message{}, fmt.Errorf("thread %q not found in channel %q", threadID, channel)
+	}
+
+	return msg, nil
+}
+
+// Presence implements the joe.PresenceAdapter interface by looking up the user
+// in Adapter.Presences. Unknown users are offline.
+func (a *Adapter) Presence(userID string) (joe.Presence, error) {
+	p, ok := a.Presences[userID]
+	if !ok {
+		return joe.PresenceOffline, nil
+	}
+
+	return p, nil
+}
+
+// Channels implements the joe.ChannelLister interface by returning
+// Adapter.ChannelList.
+func (a *Adapter) Channels() ([]joe.Channel, error) {
+	return a.ChannelList, nil
+}
+
+// Members implements the joe.MemberLister interface by looking up the channel
+// in Adapter.ChannelMembers.
+func (a *Adapter) Members(channel string) ([]joe.User, error) {
+	members, ok := a.ChannelMembers[channel]
+	if !ok {
+		return nil, fmt.Errorf("channel %q not found", channel)
+	}
+
+	return members, nil
+}
+
+// SyncCommands implements the joe.CommandSyncer interface by recording the
+// commands.
+func (a *Adapter) SyncCommands(commands []joe.CommandInfo) error {
+	a.mu.Lock()
+	a.commands = commands
+	a.mu.Unlock()
+	return nil
+}
+
+// Drain implements the joe.Drainer interface. Afterwards, all messages that
+// are passed to Adapter.Receive(…) are discarded.
+func (a *Adapter) Drain(context.Context) error {
+	a.mu.Lock()
+	a.drained = true
+	a.mu.Unlock()
+	return nil
+}
+
+// Close implements the joe.Adapter interface.
+func (a *Adapter) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.closed {
+		return joe.ErrAdapterClosed
+	}
+
+	a.closed = true
+	return nil
+}
+
+// Drained returns true if Adapter.Drain(…) was called.
+func (a *Adapter) Drained() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.drained
+}
+
+// Closed returns true if Adapter.Close() was called.
+func (a *Adapter) Closed() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.closed
+}
+
+// Messages returns all messages that have been sent via the Adapter.
+func (a *Adapter) Messages() []SentMessage {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	messages := make([]SentMessage, len(a.messages))
+	copy(messages, a.messages)
+	return messages
+}
+
+// Reactions returns all reactions that have been sent via the Adapter.
+func (a *Adapter) Reactions() []SentReaction {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	sent := make([]SentReaction, len(a.reactions))
+	copy(sent, a.reactions)
+	return sent
+}
+
+// Edits returns all edits of messages that have been sent via the Adapter.
+func (a *Adapter) Edits() []SentEdit {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	edits := make([]SentEdit, len(a.edits))
+	copy(edits, a.edits)
+	return edits
+}
+
+// Commands returns the commands that have been synced via the Adapter.
+func (a *Adapter) Commands() []joe.CommandInfo {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	commands := make([]joe.CommandInfo, len(a.commands))
+	copy(commands, a.commands)
+	return commands
+}
@@ -0,0 +1,208 @@
+package joetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-joe/joe"
+	"github.com/go-joe/joe/reactions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdapter(t *testing.T) {
+	a := NewAdapter()
+	a.UserID = "joe"
+	b := NewBot(t, a)
+
+	b.Respond("ping", func(msg joe.Message) error {
+		msg.Respond("PONG")
+		require.NoError(t, msg.RespondEphemeral("psst"))
+		return msg.React(reactions.Thumbsup)
+	})
+
+	b.Start()
+
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "ping", Channel: "general", AuthorID: "alice"})
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "ping", Channel: "general", AuthorID: "joe"}) // ignored because it is from the bot itself
+
+	assert.Equal(t, []SentMessage{
+		{Text: "PONG", Channel: "general"},
+		{Text: "psst", Channel: "general", UserID: "alice"},
+	}, a.Messages())
+
+	sent := a.Reactions()
+	require.Len(t, sent, 1)
+	assert.Equal(t, reactions.Thumbsup, sent[0].Reaction)
+	assert.Equal(t, "ping", sent[0].Message.Text)
+
+	assert.False(t, a.Closed())
+	b.Stop()
+	assert.True(t, a.Closed())
+	assert.Equal(t, joe.ErrAdapterClosed, a.Close())
+}
+
+func TestAdapter_Receive(t *testing.T) {
+	a := NewAdapter()
+	b := NewBot(t, a)
+
+	received := make(chan joe.Message, 1)
+	b.Respond("hello", func(msg joe.Message) error {
+		received <- msg
+		return nil
+	})
+
+	b.Start()
+	defer b.Stop()
+
+	a.Receive(joe.ReceiveMessageEvent{Text: "hello", Channel: "general"})
+
+	select {
+	case msg := <-received:
+		assert.Equal(t, "general", msg.Channel)
+	case <-time.After(time.Second):
+		t.Error("Timeout")
+	}
+}
+
+func TestAdapter_Receive_NotRegistered(t *testing.T) {
+	a := NewAdapter()
+	assert.Panics(t, func() {
+		a.Receive(joe.ReceiveMessageEvent{Text: "hello"})
+	})
+}
+
+func TestAdapter_ReactID(t *testing.T) {
+	a := NewAdapter()
+	b := NewBot(t, a)
+
+	require.NoError(t, b.React("general", "42", reactions.Thumbsup))
+	assert.Equal(t, []SentReaction{{
+		Reaction: reactions.Thumbsup,
+		Message:  joe.Message{ID: "42", Channel: "general"},
+	}}, a.Reactions())
+}
+
+func TestAdapter_Err(t *testing.T) {
+	a := NewAdapter()
+	a.Err = errors.New("connection lost")
+
+	assert.Equal(t, a.Err, a.Send("hello", "general"))
+	assert.Equal(t, a.Err, a.SendEphemeral("general", "alice", "hello"))
+	assert.Equal(t, a.Err, a.React(reactions.Thumbsup, joe.Message{}))
+	assert.Equal(t, a.Err, a.ReactID(reactions.Thumbsup, "general", "42"))
+	_, err := a.SendMessage("hello", "general")
+	assert.Equal(t, a.Err, err)
+	assert.Empty(t, a.Messages())
+	assert.Empty(t, a.Reactions())
+}
+
+func TestAdapter_Capabilities(t *testing.T) {
+	a := NewAdapter()
+	b := NewBot(t, a)
+
+	assert.Equal(t, []joe.Capability{
+		joe.CapabilityChannels,
+		joe.CapabilityCommands,
+		joe.CapabilityDrain,
+		joe.CapabilityEditing,
+		joe.CapabilityEphemeral,
+		joe.CapabilityMembers,
+		joe.CapabilityPresence,
+		joe.CapabilityReactByID,
+		joe.CapabilityReactions,
+		joe.CapabilitySelfAware,
+		joe.CapabilityThreads,
+	}, b.AdapterCapabilities())
+}
+
+func TestAdapter_NewProgress(t *testing.T) {
+	a := NewAdapter()
+	b := NewBot(t, a)
+
+	b.Respond("deploy", func(msg joe.Message) error {
+		p, err := msg.NewProgress("Deploying")
+		if err != nil {
+			return err
+		}
+
+		return p.Done("Deployed")
+	})
+
+	b.Start()
+	defer b.Stop()
+
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "deploy", Channel: "general"})
+	assert.Equal(t, []SentMessage{{Text: "Deployed", Channel: "general", ID: "1"}}, a.Messages())
+	assert.Equal(t, []SentEdit{{Channel: "general", MessageID: "1", Text: "Deployed"}}, a.Edits())
+	assert.Error(t, a.Edit("random", "1", "Deployed"))
+}
+
+func TestAdapter_Lookups(t *testing.T) {
+	a := NewAdapter()
+	a.ThreadRoots = map[string]joe.Message{"1234": {Text: "root", Channel: "general"}}
+	a.Presences = map[string]joe.Presence{"alice": joe.PresenceAway}
+	a.ChannelList = []joe.Channel{{ID: "general", Name: "General"}}
+	a.ChannelMembers = map[string][]joe.User{"general": {{ID: "alice"}}}
+	b := NewBot(t, a)
+
+	root, err := a.ThreadRoot("general", "1234")
+	require.NoError(t, err)
+	assert.Equal(t, "root", root.Text)
+	_, err = a.ThreadRoot("general", "5678")
+	assert.Error(t, err)
+
+	presence, err := b.UserPresence("alice")
+	require.NoError(t, err)
+	assert.Equal(t, joe.PresenceAway, presence)
+	presence, err = b.UserPresence("bob")
+	require.NoError(t, err)
+	assert.Equal(t, joe.PresenceOffline, presence)
+
+	channels, err := b.Channels()
+	require.NoError(t, err)
+	assert.Equal(t, a.ChannelList, channels)
+
+	members, err := b.ChannelMembers("general")
+	require.NoError(t, err)
+	assert.Equal(t, []joe.User{{ID: "alice"}}, members)
+	_, err = b.ChannelMembers("random")
+	assert.Error(t, err)
+}
+
+func TestAdapter_SyncCommands(t *testing.T) {
+	a := NewAdapter()
+	b := NewBot(t, a)
+	b.Respond("ping", func(joe.Message) error { return nil })
+
+	b.Start()
+	defer b.Stop()
+
+	commands := a.Commands()
+	require.Len(t, commands, 1)
+	assert.Equal(t, "^ping$", commands[0].Pattern)
+}
+
+func TestAdapter_Drain(t *testing.T) {
+	a := NewAdapter()
+	b := NewBot(t, a)
+
+	received := make(chan bool, 1)
+	b.Respond("hello", func(joe.Message) error {
+		received <- true
+		return nil
+	})
+
+	runErr := make(chan error, 1)
+	go func() { runErr <- b.Run() }()
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "ping"}) // wait until the bot is running
+
+	require.NoError(t, b.Drain(context.Background()))
+	assert.True(t, a.Drained())
+	assert.NoError(t, <-runErr)
+
+	a.Receive(joe.ReceiveMessageEvent{Text: "hello"})
+	assert.Empty(t, received, "messages after draining should be discarded")
+}
@@ -3,8 +3,10 @@ package joetest
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"io/ioutil"
+	"strings"
 	"time"
 
 	"github.com/go-joe/joe"
@@ -81,6 +83,28 @@ func (b *Bot) EmitSync(event interface{}) {
 	}
 }
 
+// SendMessage emits a joe.ReceiveMessageEvent with the given text and blocks
+// until all handlers have processed it. It returns everything the bot wrote to
+// Bot.Output while handling the message, without the trailing newline. Any
+// output that was written before the message was sent is discarded.
+//
+// Example:
+//   assert.Equal(t, "PONG", b.SendMessage("ping"))
+func (b *Bot) SendMessage(text string) string {
+	b.T.Helper()
+
+	b.ReadOutput() // discard any previous output
+	b.EmitSync(joe.ReceiveMessageEvent{Text: text})
+	return strings.TrimSuffix(b.ReadOutput(), "\n")
+}
+
+// SendMessagef is like Bot.SendMessage(…) but formats the text according to a
+// format specifier first.
+func (b *Bot) SendMessagef(format string, args ...interface{}) string {
+	b.T.Helper()
+	return b.SendMessage(fmt.Sprintf(format, args...))
+}
+
 // Start executes the Bot.Run() function and stores its error result in a channel
 // so the caller can eventually execute Bot.Stop() and receive the result.
 // This function blocks until the event handler is actually running and emits
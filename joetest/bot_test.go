@@ -28,6 +28,26 @@ func TestBot(t *testing.T) {
 	assert.Equal(t, []TestEvent{{N: 123}}, seenEvents)
 }
 
+func TestBot_SendMessage(t *testing.T) {
+	b := NewBot(t)
+	b.Respond("ping", func(msg joe.Message) error {
+		msg.Respond("PONG")
+		return nil
+	})
+	b.Respond("hello (.+)", func(msg joe.Message) error {
+		msg.Respond("Hello %s", msg.Matches[0])
+		msg.Respond("How are you?")
+		return nil
+	})
+
+	b.Start()
+	defer b.Stop()
+
+	assert.Equal(t, "PONG", b.SendMessage("ping"))
+	assert.Equal(t, "Hello world\nHow are you?", b.SendMessagef("hello %s", "world"))
+	assert.Equal(t, "", b.SendMessage("something else"))
+}
+
 func TestBotEmitSyncTimeout(t *testing.T) {
 	mock := new(mockT)
 	b := NewBot(mock)
@@ -2,6 +2,7 @@ package joetest
 
 import (
 	"context"
+	"reflect"
 	"sync"
 	"time"
 
@@ -16,6 +17,7 @@ type Brain struct {
 	mu         sync.Mutex
 	events     []interface{}
 	eventsChan chan joe.Event
+	recorded   chan struct{} // closed and replaced whenever a new event is recorded
 }
 
 // NewBrain creates a new Brain that can be used for unit testing. The Brain
@@ -28,6 +30,7 @@ func NewBrain(t TestingT) *Brain {
 	b := &Brain{
 		Brain:      joe.NewBrain(logger),
 		eventsChan: make(chan joe.Event, 100),
+		recorded:   make(chan struct{}),
 	}
 
 	initialized := make(chan bool)
@@ -57,6 +60,8 @@ func (b *Brain) observeEvent(evt interface{}) {
 
 	b.mu.Lock()
 	b.events = append(b.events, evt)
+	close(b.recorded)
+	b.recorded = make(chan struct{})
 	b.mu.Unlock()
 }
 
@@ -83,3 +88,68 @@ func (b *Brain) RecordedEvents() []interface{} {
 func (b *Brain) Events() <-chan joe.Event {
 	return b.eventsChan
 }
+
+// AssertEventEmitted asserts that the Brain has recorded at least one event of
+// the same type as the given event (e.g. joe.ReceiveMessageEvent{}). The first
+// matching event is returned so the caller can make further assertions on it.
+func (b *Brain) AssertEventEmitted(t TestingT, eventType interface{}) (interface{}, bool) {
+	t.Helper()
+
+	evt, ok := b.findEvent(reflect.TypeOf(eventType))
+	if !ok {
+		t.Errorf("Expected an event of type %T to be emitted but it was not", eventType)
+	}
+
+	return evt, ok
+}
+
+// AssertNoEvent asserts that the Brain has not recorded any event of the same
+// type as the given event.
+func (b *Brain) AssertNoEvent(t TestingT, eventType interface{}) bool {
+	t.Helper()
+
+	evt, ok := b.findEvent(reflect.TypeOf(eventType))
+	if ok {
+		t.Errorf("Expected no event of type %T to be emitted but got %+v", eventType, evt)
+	}
+
+	return !ok
+}
+
+// WaitForEvent blocks until the Brain has recorded an event of the same type
+// as the given event or until the timeout is reached. Events that have been
+// recorded before this function was called are also taken into account. The
+// returned boolean is false if no matching event was recorded in time.
+func (b *Brain) WaitForEvent(eventType interface{}, timeout time.Duration) (interface{}, bool) {
+	typ := reflect.TypeOf(eventType)
+	deadline := time.After(timeout)
+
+	for {
+		b.mu.Lock()
+		recorded := b.recorded
+		b.mu.Unlock()
+
+		// We must check the events after we fetched the recorded channel so we
+		// do not miss any event that is recorded in between.
+		if evt, ok := b.findEvent(typ); ok {
+			return evt, true
+		}
+
+		select {
+		case <-recorded:
+			// check again
+		case <-deadline:
+			return nil, false
+		}
+	}
+}
+
+func (b *Brain) findEvent(typ reflect.Type) (interface{}, bool) {
+	for _, evt := range b.RecordedEvents() {
+		if reflect.TypeOf(evt) == typ {
+			return evt, true
+		}
+	}
+
+	return nil, false
+}
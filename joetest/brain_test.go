@@ -2,7 +2,9 @@ package joetest
 
 import (
 	"testing"
+	"time"
 
+	"github.com/go-joe/joe"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -28,3 +30,55 @@ func TestBrain(t *testing.T) {
 	actualEvents := b.RecordedEvents()
 	assert.Equal(t, expectedEvents, actualEvents)
 }
+
+type OtherEvent struct{}
+
+func TestBrain_AssertEventEmitted(t *testing.T) {
+	b := NewBrain(t)
+	b.Emit(TestEvent{42})
+	b.Finish()
+
+	evt, ok := b.AssertEventEmitted(t, TestEvent{})
+	assert.True(t, ok)
+	assert.Equal(t, TestEvent{42}, evt)
+
+	mock := new(mockT)
+	_, ok = b.AssertEventEmitted(mock, OtherEvent{})
+	assert.False(t, ok)
+	assert.Equal(t, []string{"Expected an event of type joetest.OtherEvent to be emitted but it was not"}, mock.Errors)
+}
+
+func TestBrain_AssertNoEvent(t *testing.T) {
+	b := NewBrain(t)
+	b.Emit(TestEvent{42})
+	b.Finish()
+
+	assert.True(t, b.AssertNoEvent(t, OtherEvent{}))
+
+	mock := new(mockT)
+	assert.False(t, b.AssertNoEvent(mock, TestEvent{}))
+	assert.Equal(t, []string{"Expected no event of type joetest.TestEvent to be emitted but got {N:42}"}, mock.Errors)
+}
+
+func TestBrain_WaitForEvent(t *testing.T) {
+	b := NewBrain(t)
+	defer b.Finish()
+
+	b.Emit(TestEvent{1})
+	evt, ok := b.WaitForEvent(TestEvent{}, time.Second)
+	assert.True(t, ok)
+	assert.Equal(t, TestEvent{1}, evt)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		b.Emit(OtherEvent{})
+	}()
+
+	evt, ok = b.WaitForEvent(OtherEvent{}, time.Second)
+	assert.True(t, ok)
+	assert.Equal(t, OtherEvent{}, evt)
+
+	evt, ok = b.WaitForEvent(joe.ReceiveMessageEvent{}, 10*time.Millisecond)
+	assert.False(t, ok)
+	assert.Nil(t, evt)
+}
@@ -0,0 +1,131 @@
+package joetest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/go-joe/joe"
+)
+
+// Clock is a fake joe.Clock for unit tests. Time only passes when the test
+// calls Clock.Advance(…) or Clock.Set(…). It can be injected into a bot via the
+// joe.WithClock(…) option:
+//   clock := joetest.NewClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+//   b := joetest.NewBot(t, joe.WithClock(clock))
+type Clock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+// NewClock creates a new Clock which starts at the given time.
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now implements the joe.Clock interface by returning the current fake time.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After implements the joe.Clock interface. The returned channel receives the
+// current fake time once the Clock was advanced by at least d.
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+// NewTimer implements the joe.Clock interface. The timer fires once the Clock
+// was advanced by at least d.
+func (c *Clock) NewTimer(d time.Duration) joe.Timer {
+	t := &fakeTimer{clock: c, c: make(chan time.Time, 1)}
+	t.Reset(d)
+	return t
+}
+
+// Advance moves the Clock forward by the given duration and fires all timers
+// that expired in the meantime.
+func (c *Clock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set moves the Clock to the given time and fires all timers that expired in
+// the meantime.
+func (c *Clock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = now
+	c.fireExpired()
+}
+
+// Timers returns the number of timers that have not fired yet. This can be
+// used to wait until the code under test has created a timer before the Clock
+// is advanced.
+func (c *Clock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// fireExpired fires and removes all expired timers. The caller must hold the
+// lock of the Clock.
+func (c *Clock) fireExpired() {
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		if t.deadline.After(c.now) {
+			pending = append(pending, t)
+			continue
+		}
+
+		select {
+		case t.c <- c.now:
+		default: // the channel is buffered so this only happens if nobody received the last value
+		}
+	}
+
+	c.timers = pending
+}
+
+// remove removes the timer from the Clock and returns true if it was pending.
+// The caller must hold the lock of the Clock.
+func (c *Clock) remove(timer *fakeTimer) bool {
+	for i, t := range c.timers {
+		if t == timer {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+type fakeTimer struct {
+	clock    *Clock
+	c        chan time.Time
+	deadline time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.clock.remove(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	active := c.remove(t)
+	t.deadline = c.now.Add(d)
+	c.timers = append(c.timers, t)
+	c.fireExpired()
+
+	return active
+}
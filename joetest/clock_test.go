@@ -0,0 +1,53 @@
+package joetest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClock(t *testing.T) {
+	start := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := NewClock(start)
+	assert.Equal(t, start, c.Now())
+
+	after := c.After(time.Minute)
+	timer := c.NewTimer(time.Hour)
+	assert.Equal(t, 2, c.Timers())
+
+	c.Advance(59 * time.Second)
+	assertNotFired(t, after)
+
+	c.Advance(time.Second)
+	assert.Equal(t, start.Add(time.Minute), <-after)
+	assert.Equal(t, 1, c.Timers())
+
+	assert.True(t, timer.Stop())
+	assert.False(t, timer.Stop())
+	c.Advance(time.Hour)
+	assertNotFired(t, timer.C())
+
+	assert.False(t, timer.Reset(time.Second))
+	assert.True(t, timer.Reset(2*time.Second))
+	c.Set(start.Add(2*time.Hour + 2*time.Second))
+	assert.Equal(t, start.Add(2*time.Hour+2*time.Second), <-timer.C())
+	assert.Equal(t, 0, c.Timers())
+}
+
+func TestClock_ImmediateTimer(t *testing.T) {
+	start := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := NewClock(start)
+
+	assert.Equal(t, start, <-c.After(0))
+	assert.Equal(t, 0, c.Timers())
+}
+
+func assertNotFired(t *testing.T, c <-chan time.Time) {
+	t.Helper()
+	select {
+	case <-c:
+		t.Error("timer should not have fired")
+	default:
+	}
+}
@@ -0,0 +1,108 @@
+package joe
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// WithLoopGuard is an option to protect the bot against infinite response
+// loops. Such loops can happen if the bot receives its own messages (e.g.
+// because the Adapter does not implement the SelfAwareAdapter interface) or if
+// it talks to another bot that repeats what it was told. If this option is
+// used, the bot remembers all messages it sent during the given window and
+// ignores any received message that has exactly the same text in the same
+// channel. A warning is logged whenever a message is ignored this way.
+//
+// The check runs once per message in an event handler that is executed before
+// all other handlers of the ReceiveMessageEvent. Therefore, other than the
+// handlers that are registered via Bot.Respond(…) and its variants, also the
+// handlers that are registered directly at the Brain do not receive ignored
+// messages.
+//
+// This option wraps the final Adapter of the bot so it can be passed to
+// joe.New(…) in any order.
+func WithLoopGuard(window time.Duration) Module {
+	return ModuleFunc(func(conf *Config) error {
+		if window <= 0 {
+			return errors.New("loop guard window must be positive")
+		}
+
+		conf.loopGuardWindow = window
+		return nil
+	})
+}
+
+// loopGuard remembers the recently sent messages of the bot.
+type loopGuard struct {
+	clock  Clock
+	window time.Duration
+
+	mu   sync.Mutex
+	sent map[loopGuardKey]time.Time // the time each message was last sent
+}
+
+type loopGuardKey struct {
+	channel, text string
+}
+
+func newLoopGuard(clock Clock, window time.Duration) *loopGuard {
+	return &loopGuard{
+		clock:  clock,
+		window: window,
+		sent:   map[loopGuardKey]time.Time{},
+	}
+}
+
+// registerLoopGuard registers the event handler that stops the execution of
+// all other handlers if the received message was recently sent by the bot.
+func (b *Bot) registerLoopGuard() {
+	b.Brain.RegisterHandler(func(ctx context.Context, evt ReceiveMessageEvent) {
+		// Messages that are ignored anyway should not be reported.
+		if b.isSelfMessage(evt) || (b.ignoreBots && evt.AuthorIsBot) {
+			return
+		}
+
+		if !b.loopGuard.isLoop(evt.Channel, evt.Text) {
+			return
+		}
+
+		b.Logger.Warn("Ignoring message that the bot sent itself recently to prevent a response loop",
+			zap.String("channel", evt.Channel),
+			zap.String("author", evt.AuthorID),
+		)
+		FinishEventContent(ctx)
+	}, HandlerPriority(math.MaxInt32))
+}
+
+// wrap is used by a wrappedAdapter to record every message before it is sent.
+// We record before sending so we catch echoes that are received before the
+// Adapter returns.
+func (g *loopGuard) wrap(channel, text string, send func() error) error {
+	now := g.clock.Now()
+
+	g.mu.Lock()
+	for key, sent := range g.sent {
+		if now.Sub(sent) > g.window {
+			delete(g.sent, key)
+		}
+	}
+	g.sent[loopGuardKey{channel: channel, text: text}] = now
+	g.mu.Unlock()
+
+	return send()
+}
+
+// isLoop returns true if the bot sent the given text to the channel within the
+// configured window.
+func (g *loopGuard) isLoop(channel, text string) bool {
+	g.mu.Lock()
+	sent, ok := g.sent[loopGuardKey{channel: channel, text: text}]
+	g.mu.Unlock()
+
+	return ok && g.clock.Now().Sub(sent) <= g.window
+}
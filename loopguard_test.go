@@ -0,0 +1,100 @@
+package joe_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-joe/joe"
+	"github.com/go-joe/joe/joetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestWithLoopGuard(t *testing.T) {
+	clock := joetest.NewClock(time.Now())
+	b := joetest.NewBot(t, joe.WithLoopGuard(time.Minute), joe.WithClock(clock))
+
+	var calls int
+	b.Respond("hello", func(msg joe.Message) error {
+		calls++
+		msg.Respond("hello")
+		return nil
+	})
+
+	b.Start()
+	defer b.Stop()
+
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "hello", Channel: "test"})
+	assert.Equal(t, 1, calls)
+
+	// The bot receives its own message and must not respond to it again.
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "hello", Channel: "test"})
+	assert.Equal(t, 1, calls)
+
+	// The same text in another channel was not sent by the bot.
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "hello", Channel: "other"})
+	assert.Equal(t, 2, calls)
+
+	clock.Advance(time.Minute + time.Second)
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "hello", Channel: "test"})
+	assert.Equal(t, 3, calls)
+}
+
+func TestWithLoopGuard_SingleCheck(t *testing.T) {
+	core, logs := observer.New(zap.WarnLevel)
+	b := joetest.NewBot(t, joe.WithLoopGuard(time.Minute), joe.WithLogger(zap.New(core)))
+
+	var calls int
+	for _, cmd := range []string{"ping", "hello", "help"} {
+		b.Respond(cmd, func(msg joe.Message) error {
+			calls++
+			msg.Respond("hello")
+			return nil
+		})
+	}
+
+	var rawCalls int
+	b.Brain.RegisterHandler(func(joe.ReceiveMessageEvent) {
+		rawCalls++
+	})
+
+	b.Start()
+	defer b.Stop()
+
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "hello", Channel: "test"})
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "hello", Channel: "test"})
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, 0, rawCalls, "the matching command should stop the first message")
+	assert.Equal(t, 1, logs.FilterMessageSnippet("Ignoring message").Len(), "the loop should only be reported once")
+
+	// Messages that do not match any command are still handled.
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "unknown", Channel: "test"})
+	assert.Equal(t, 1, rawCalls)
+}
+
+func TestWithLoopGuard_Disabled(t *testing.T) {
+	b := joetest.NewBot(t)
+
+	var calls int
+	b.Respond("hello", func(msg joe.Message) error {
+		calls++
+		msg.Respond("hello")
+		return nil
+	})
+
+	b.Start()
+	defer b.Stop()
+
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "hello", Channel: "test"})
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "hello", Channel: "test"})
+	assert.Equal(t, 2, calls)
+}
+
+func TestWithLoopGuard_InvalidWindow(t *testing.T) {
+	b := joetest.NewBot(t, joe.WithLoopGuard(0))
+	err := b.Run()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "loop guard window must be positive")
+}
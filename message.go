@@ -3,13 +3,26 @@ package joe
 import (
 	"context"
 	"fmt"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
 
 	"github.com/go-joe/joe/reactions"
 )
 
+// DefaultMaxMessageLength is the default maximum length of a single message
+// that is sent via Message.RespondPaged(…).
+const DefaultMaxMessageLength = 4000
+
 // A Message is automatically created from a ReceiveMessageEvent and then passed
 // to the RespondFunc that was registered via Bot.Respond(…) or Bot.RespondRegex(…)
 // when the message matches the regular expression of the handler.
+//
+// The Context of the Message is the same context that is passed to the event
+// handler by the Brain. It is canceled when the handler timeout (see
+// WithHandlerTimeout(…)) is reached or when the bot is shutting down, so long
+// running handlers should respect it.
 type Message struct {
 	Context  context.Context
 	ID       string // The ID of the message, identifying it at least uniquely within the Channel
@@ -17,9 +30,33 @@ type Message struct {
 	AuthorID string
 	Channel  string
 	Matches  []string    // contains all sub matches of the regular expression that matched the Text
+	Pattern  string      // the regular expression that matched the Text as it was passed to Bot.RespondRegex(…)
 	Data     interface{} // corresponds to the ReceiveMessageEvent.Data field
 
-	adapter Adapter
+	AuthorIsBot bool   // corresponds to the ReceiveMessageEvent.AuthorIsBot field
+	ThreadID    string // corresponds to the ReceiveMessageEvent.ThreadID field
+
+	Attachments []Attachment // corresponds to the ReceiveMessageEvent.Attachments field
+
+	adapter     Adapter
+	maxLen      int  // maximum length of a single message, used by RespondPaged
+	pageNumbers bool // if true, RespondPaged adds the page suffix
+	localizer   messageLocalizer
+
+	conversations *conversations // used by Await
+}
+
+// Deadline returns the time at which the Context of the message is canceled
+// because the handler timeout is reached. The boolean return value is false if
+// there is no deadline (e.g. because the handler timeout is disabled). This can
+// be used by long running handlers to do as much work as possible and then
+// report their progress before the handler is canceled.
+func (msg *Message) Deadline() (time.Time, bool) {
+	if msg.Context == nil {
+		return time.Time{}, false
+	}
+
+	return msg.Context.Deadline()
 }
 
 // Respond is a helper function to directly send a response back to the channel
@@ -40,14 +77,246 @@ func (msg *Message) RespondE(text string, args ...interface{}) error {
 	return msg.adapter.Send(text, msg.Channel)
 }
 
+// RespondToChannel is like Message.Respond(…) but the response is sent to the
+// given channel instead of the channel the message originated from (e.g. to
+// post the result of a command that was received in a direct message to a
+// public channel). This function ignores any error when sending the response.
+// If you want to handle the error use Message.RespondToChannelE instead.
+func (msg *Message) RespondToChannel(channel, text string, args ...interface{}) {
+	_ = msg.RespondToChannelE(channel, text, args...)
+}
+
+// RespondToChannelE is like Message.RespondToChannel(…) but any error when
+// sending the response is returned.
+func (msg *Message) RespondToChannelE(channel, text string, args ...interface{}) error {
+	if len(args) > 0 {
+		text = fmt.Sprintf(text, args...)
+	}
+
+	return msg.adapter.Send(text, channel)
+}
+
+// RespondEphemeral sends a response back to the channel the message originated
+// from that is only visible to the author of the message. If the Adapter does
+// not implement the EphemeralAdapter interface, the response is sent as a normal
+// message to the channel instead.
+func (msg *Message) RespondEphemeral(text string, args ...interface{}) error {
+	if len(args) > 0 {
+		text = fmt.Sprintf(text, args...)
+	}
+
+	adapter, ok := msg.adapter.(EphemeralAdapter)
+	if !ok || !AdapterSupports(msg.adapter, CapabilityEphemeral) {
+		return msg.adapter.Send(text, msg.Channel)
+	}
+
+	return adapter.SendEphemeral(msg.Channel, msg.AuthorID, text)
+}
+
 // React attempts to let the Adapter attach the given reaction to this message.
 // If the adapter does not support this feature this function will return
 // ErrNotImplemented.
 func (msg *Message) React(reaction reactions.Reaction) error {
 	adapter, ok := msg.adapter.(ReactionAwareAdapter)
-	if !ok {
+	if !ok || !AdapterSupports(msg.adapter, CapabilityReactions) {
 		return ErrNotImplemented
 	}
 
 	return adapter.React(reaction, *msg)
 }
+
+// ThreadRoot returns the message that started the thread in which this message
+// was posted. The returned Message can be used to respond like any other
+// message. If the Adapter does not support reading threads, ErrNotImplemented
+// is returned. If this message was not posted in a thread, ErrNotInThread is
+// returned.
+func (msg *Message) ThreadRoot() (Message, error) {
+	adapter, ok := msg.adapter.(ThreadReaderAdapter)
+	if !ok || !AdapterSupports(msg.adapter, CapabilityThreads) {
+		return Message{}, ErrNotImplemented
+	}
+
+	if msg.ThreadID == "" {
+		return Message{}, ErrNotInThread
+	}
+
+	root, err := adapter.ThreadRoot(msg.Channel, msg.ThreadID)
+	if err != nil {
+		return Message{}, err
+	}
+
+	if root.Channel == "" {
+		root.Channel = msg.Channel
+	}
+	if root.ThreadID == "" {
+		root.ThreadID = msg.ThreadID
+	}
+
+	root.Context = msg.Context
+	root.adapter = msg.adapter
+	root.maxLen = msg.maxLen
+	root.pageNumbers = msg.pageNumbers
+	root.localizer = msg.localizer
+	root.conversations = msg.conversations
+	return root, nil
+}
+
+// RespondTemplate executes the given text/template and sends the result back
+// to the channel the message originated from. Within the template you can
+// access the following fields of the message:
+//
+//	{{.ID}}, {{.Text}}, {{.AuthorID}}, {{.Channel}}, {{.Matches}}, {{.Pattern}}
+//
+// The data argument is available via {{.Data}}. If the template cannot be
+// parsed or executed an error is returned and no response is sent.
+//
+// Example:
+//
+//	msg.RespondTemplate("Hello {{.AuthorID}}, {{index .Matches 0}} is {{.Data}}", value)
+func (msg *Message) RespondTemplate(tmpl string, data interface{}) error {
+	t, err := template.New("response").Parse(tmpl)
+	if err != nil {
+		return fmt.Errorf("failed to parse response template: %w", err)
+	}
+
+	var text strings.Builder
+	err = t.Execute(&text, templateData{
+		ID:       msg.ID,
+		Text:     msg.Text,
+		AuthorID: msg.AuthorID,
+		Channel:  msg.Channel,
+		Matches:  msg.Matches,
+		Pattern:  msg.Pattern,
+		Data:     data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to execute response template: %w", err)
+	}
+
+	return msg.adapter.Send(text.String(), msg.Channel)
+}
+
+// templateData is passed to the template in Message.RespondTemplate(…).
+type templateData struct {
+	ID       string
+	Text     string
+	AuthorID string
+	Channel  string
+	Matches  []string
+	Pattern  string
+	Data     interface{}
+}
+
+// RespondPaged sends the given lines back to the channel the message originated
+// from. The lines are joined with newlines and split into as many messages as
+// necessary so that no single message exceeds the maximum message length in
+// characters (see WithMaxMessageLength(…)). Lines that are too long to fit into
+// a single message are split. If the bot was configured with the
+// WithPageNumbers() option and the response needs more than one message, each
+// message ends with a "(page n/m)" suffix.
+func (msg *Message) RespondPaged(lines []string) error {
+	maxLen := msg.maxLen
+	if maxLen <= 0 {
+		maxLen = DefaultMaxMessageLength
+	}
+
+	pages := paginate(lines, maxLen, msg.pageNumbers)
+	for i, page := range pages {
+		if msg.pageNumbers && len(pages) > 1 {
+			page += fmt.Sprintf("\n(page %d/%d)", i+1, len(pages))
+		}
+
+		err := msg.adapter.Send(page, msg.Channel)
+		if err != nil {
+			return fmt.Errorf("failed to send page %d/%d: %w", i+1, len(pages), err)
+		}
+	}
+
+	return nil
+}
+
+// paginate joins the lines into pages that are no longer than maxLen
+// characters. If numbered is true, this includes the "(page n/m)" suffix that
+// is added if there is more than a single page.
+func paginate(lines []string, maxLen int, numbered bool) []string {
+	pages := splitPages(lines, maxLen)
+	for numbered {
+		if len(pages) <= 1 {
+			return pages
+		}
+
+		// Reserve enough space for the page suffix. Since the suffix may push
+		// lines onto more pages we repeat until the number of pages is stable.
+		suffix := utf8.RuneCountInString(fmt.Sprintf("\n(page %d/%d)", len(pages), len(pages)))
+		if suffix >= maxLen {
+			return pages
+		}
+
+		n := len(pages)
+		pages = splitPages(lines, maxLen-suffix)
+		if len(pages) <= n {
+			return pages
+		}
+	}
+
+	return pages
+}
+
+// splitPages joins the lines into pages that are no longer than maxLen
+// characters. Blank lines are kept, also at the start of a page.
+func splitPages(lines []string, maxLen int) []string {
+	var (
+		pages   []string
+		page    strings.Builder
+		pageLen int // in characters
+		n       int // number of lines on the page, including blank lines
+	)
+
+	flush := func() {
+		if n > 0 {
+			pages = append(pages, page.String())
+		}
+		page.Reset()
+		pageLen, n = 0, 0
+	}
+
+	for _, line := range lines {
+		lineLen := utf8.RuneCountInString(line)
+		if n > 0 && pageLen+1+lineLen > maxLen {
+			flush()
+		}
+
+		for lineLen > maxLen {
+			// The line does not fit on a single page so we split it without
+			// breaking any multi-byte characters.
+			i := runeOffset(line, maxLen)
+			flush()
+			pages = append(pages, line[:i])
+			line = line[i:]
+			lineLen -= maxLen
+		}
+
+		if n > 0 {
+			page.WriteString("\n")
+			pageLen++
+		}
+		page.WriteString(line)
+		pageLen += lineLen
+		n++
+	}
+
+	flush()
+	return pages
+}
+
+// runeOffset returns the byte offset of the n-th character of s.
+func runeOffset(s string, n int) int {
+	for i := range s {
+		if n == 0 {
+			return i
+		}
+		n--
+	}
+
+	return len(s)
+}
@@ -1,12 +1,15 @@
 package joe
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/go-joe/joe/reactions"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMessage_Respond(t *testing.T) {
@@ -30,6 +33,208 @@ func TestMessage_RespondE(t *testing.T) {
 	a.AssertExpectations(t)
 }
 
+func TestMessage_RespondToChannel(t *testing.T) {
+	a := new(MockAdapter)
+	msg := Message{adapter: a, Channel: "direct"}
+
+	a.On("Send", "Deployed version 42", "general").Return(nil)
+	msg.RespondToChannel("general", "Deployed version %d", 42)
+	a.AssertExpectations(t)
+}
+
+func TestMessage_RespondToChannelE(t *testing.T) {
+	a := new(MockAdapter)
+	msg := Message{adapter: a, Channel: "direct"}
+
+	err := errors.New("channel not found")
+	a.On("Send", "Hello world", "general").Return(err)
+	actual := msg.RespondToChannelE("general", "Hello world")
+
+	assert.Equal(t, err, actual)
+	a.AssertExpectations(t)
+}
+
+func TestMessage_Deadline(t *testing.T) {
+	var msg Message
+	_, ok := msg.Deadline()
+	assert.False(t, ok, "message without context should not have a deadline")
+
+	msg.Context = context.Background()
+	_, ok = msg.Deadline()
+	assert.False(t, ok)
+
+	deadline := time.Now().Add(time.Minute)
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	msg.Context = ctx
+	actual, ok := msg.Deadline()
+	assert.True(t, ok)
+	assert.Equal(t, deadline, actual)
+}
+
+func TestMessage_RespondTemplate(t *testing.T) {
+	a := new(MockAdapter)
+	msg := Message{
+		adapter:  a,
+		Channel:  "test",
+		AuthorID: "alice",
+		Matches:  []string{"foo", "bar"},
+	}
+
+	a.On("Send", "Hello alice, foo is bar in test: 42", "test").Return(nil)
+	err := msg.RespondTemplate("Hello {{.AuthorID}}, {{index .Matches 0}} is {{index .Matches 1}} in {{.Channel}}: {{.Data}}", 42)
+	assert.NoError(t, err)
+	a.AssertExpectations(t)
+}
+
+func TestMessage_RespondTemplate_Errors(t *testing.T) {
+	a := new(MockAdapter)
+	msg := Message{adapter: a, Channel: "test"}
+
+	err := msg.RespondTemplate("Hello {{.AuthorID", nil)
+	assert.Error(t, err)
+	assert.Regexp(t, "^failed to parse response template: ", err.Error())
+
+	err = msg.RespondTemplate("Hello {{index .Matches 5}}", nil)
+	assert.Error(t, err)
+	assert.Regexp(t, "^failed to execute response template: ", err.Error())
+
+	a.AssertExpectations(t) // nothing should have been sent
+}
+
+func TestMessage_RespondPaged(t *testing.T) {
+	a := new(MockAdapter)
+	msg := Message{adapter: a, Channel: "test"}
+
+	a.On("Send", "foo\nbar\nbaz", "test").Return(nil)
+	err := msg.RespondPaged([]string{"foo", "bar", "baz"})
+	assert.NoError(t, err)
+	a.AssertExpectations(t)
+}
+
+func TestMessage_RespondPaged_MultiplePages(t *testing.T) {
+	a := new(MockAdapter)
+	msg := Message{adapter: a, Channel: "test", maxLen: 22, pageNumbers: true}
+
+	a.On("Send", "aaaaa\nbbbbb\n(page 1/3)", "test").Return(nil)
+	a.On("Send", "ccccc\nddddd\n(page 2/3)", "test").Return(nil)
+	a.On("Send", "eeeee\n(page 3/3)", "test").Return(nil)
+	err := msg.RespondPaged([]string{"aaaaa", "bbbbb", "ccccc", "ddddd", "eeeee"})
+	assert.NoError(t, err)
+	a.AssertExpectations(t)
+}
+
+func TestMessage_RespondPaged_WithoutPageNumbers(t *testing.T) {
+	a := new(MockAdapter)
+	msg := Message{adapter: a, Channel: "test", maxLen: 11}
+
+	a.On("Send", "aaaaa\nbbbbb", "test").Return(nil)
+	a.On("Send", "ccccc", "test").Return(nil)
+	err := msg.RespondPaged([]string{"aaaaa", "bbbbb", "ccccc"})
+	assert.NoError(t, err)
+	a.AssertExpectations(t)
+}
+
+func TestMessage_RespondPaged_BlankLine(t *testing.T) {
+	a := new(MockAdapter)
+	msg := Message{adapter: a, Channel: "test"}
+
+	a.On("Send", "", "test").Return(nil).Once()
+	err := msg.RespondPaged([]string{""})
+	assert.NoError(t, err)
+	a.AssertExpectations(t)
+}
+
+func TestMessage_RespondPaged_Error(t *testing.T) {
+	a := new(MockAdapter)
+	msg := Message{adapter: a, Channel: "test", maxLen: 14, pageNumbers: true}
+
+	err := errors.New("a wild issue occurred")
+	a.On("Send", mock.Anything, "test").Return(err).Once()
+	actual := msg.RespondPaged([]string{"foo bar", "baz qux"})
+
+	assert.EqualError(t, actual, "failed to send page 1/6: a wild issue occurred")
+	a.AssertExpectations(t)
+}
+
+func TestPaginate(t *testing.T) {
+	cases := map[string]struct {
+		lines    []string
+		maxLen   int
+		numbered bool
+		want     []string
+	}{
+		"empty": {
+			lines:  nil,
+			maxLen: 10,
+			want:   nil,
+		},
+		"single_page": {
+			lines:  []string{"foo", "bar"},
+			maxLen: 10,
+			want:   []string{"foo\nbar"},
+		},
+		"long_line": {
+			lines:  []string{"0123456789abcdefghij"},
+			maxLen: 15,
+			want:   []string{"0123456789abcde", "fghij"},
+		},
+		"long_line_numbered": {
+			lines:    []string{"0123456789abcdefghij"},
+			maxLen:   15,
+			numbered: true,
+			want:     []string{"0123", "4567", "89ab", "cdef", "ghij"},
+		},
+		"multi_byte": {
+			lines:  []string{"äöüäöüäöü", "äö"},
+			maxLen: 4,
+			want:   []string{"äöüä", "öüäö", "ü\näö"},
+		},
+		"blank_lines": {
+			lines:  []string{"foo", "", "bar", "", "", "baz"},
+			maxLen: 8,
+			want:   []string{"foo\n\nbar", "\n\nbaz"},
+		},
+		"only_blank_line": {
+			lines:  []string{""},
+			maxLen: 4,
+			want:   []string{""},
+		},
+		"trailing_blank_line": {
+			lines:  []string{"aaaa", ""},
+			maxLen: 4,
+			want:   []string{"aaaa", ""},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, c.want, paginate(c.lines, c.maxLen, c.numbered))
+		})
+	}
+}
+
+func TestMessage_RespondEphemeral(t *testing.T) {
+	a := new(ExtendedMockAdapter)
+	msg := Message{adapter: a, Channel: "test", AuthorID: "alice"}
+
+	a.On("SendEphemeral", "test", "alice", "Hello world, The Answer is 42").Return(nil)
+	err := msg.RespondEphemeral("Hello %s, The Answer is %d", "world", 42)
+	assert.NoError(t, err)
+	a.AssertExpectations(t)
+}
+
+func TestMessage_RespondEphemeral_NotImplemented(t *testing.T) {
+	a := new(MockAdapter)
+	msg := Message{adapter: a, Channel: "test", AuthorID: "alice"}
+
+	a.On("Send", "Hello world", "test").Return(nil)
+	err := msg.RespondEphemeral("Hello world")
+	assert.NoError(t, err)
+	a.AssertExpectations(t)
+}
+
 func TestMessage_React_NotImplemented(t *testing.T) {
 	a := new(MockAdapter)
 	msg := Message{adapter: a}
@@ -51,6 +256,45 @@ func TestMessage_React(t *testing.T) {
 	a.AssertExpectations(t)
 }
 
+func TestMessage_ThreadRoot(t *testing.T) {
+	a := new(ExtendedMockAdapter)
+	ctx := context.Background()
+	msg := Message{Context: ctx, adapter: a, Channel: "general", ThreadID: "1234", maxLen: 100, pageNumbers: true}
+
+	a.On("ThreadRoot", "general", "1234").Return(Message{ID: "1234", Text: "deploy failed", AuthorID: "alice"}, nil)
+	root, err := msg.ThreadRoot()
+	require.NoError(t, err)
+	assert.Equal(t, "1234", root.ID)
+	assert.Equal(t, "deploy failed", root.Text)
+	assert.Equal(t, "alice", root.AuthorID)
+	assert.Equal(t, "general", root.Channel)
+	assert.Equal(t, "1234", root.ThreadID)
+	assert.Equal(t, ctx, root.Context)
+	assert.Equal(t, 100, root.maxLen)
+	assert.True(t, root.pageNumbers, "the root message should respect WithPageNumbers()")
+
+	a.On("Send", "Looking into it", "general").Return(nil)
+	assert.NoError(t, root.RespondE("Looking into it"), "the root message should be able to respond")
+	a.AssertExpectations(t)
+}
+
+func TestMessage_ThreadRoot_Errors(t *testing.T) {
+	msg := Message{adapter: new(MockAdapter), ThreadID: "1234"}
+	_, err := msg.ThreadRoot()
+	assert.Equal(t, ErrNotImplemented, err)
+
+	a := new(ExtendedMockAdapter)
+	msg = Message{adapter: a, Channel: "general"}
+	_, err = msg.ThreadRoot()
+	assert.Equal(t, ErrNotInThread, err)
+
+	msg.ThreadID = "1234"
+	a.On("ThreadRoot", "general", "1234").Return(Message{}, errors.New("message not found"))
+	_, err = msg.ThreadRoot()
+	assert.EqualError(t, err, "message not found")
+	a.AssertExpectations(t)
+}
+
 type MockAdapter struct {
 	mock.Mock
 }
@@ -77,3 +321,13 @@ func (a *ExtendedMockAdapter) React(r reactions.Reaction, msg Message) error {
 	args := a.Called(r, msg)
 	return args.Error(0)
 }
+
+func (a *ExtendedMockAdapter) SendEphemeral(channel, userID, text string) error {
+	args := a.Called(channel, userID, text)
+	return args.Error(0)
+}
+
+func (a *ExtendedMockAdapter) ThreadRoot(channel, threadID string) (Message, error) {
+	args := a.Called(channel, threadID)
+	return args.Get(0).(Message), args.Error(1)
+}
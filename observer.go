@@ -0,0 +1,51 @@
+package joe
+
+import (
+	"time"
+)
+
+// An Observer is notified about all events that are handled by the Brain and
+// all messages that are sent via the Adapter. Observers can be used to collect
+// metrics (e.g. via Prometheus) without having to change any handlers. All
+// functions are called synchronously so implementations should return quickly.
+type Observer interface {
+	// ObserveEvent is called after an event was processed by all handlers.
+	ObserveEvent(eventType string, handlers int, duration time.Duration)
+
+	// ObserveHandler is called after a single handler processed an event. The
+	// error is nil if the handler succeeded.
+	ObserveHandler(eventType, handler string, duration time.Duration, err error)
+
+	// ObserveSend is called after a message was sent via the Adapter. The
+	// error is nil if the message was sent successfully.
+	ObserveSend(channel string, duration time.Duration, err error)
+}
+
+// WithObserver is an option to register an Observer that is notified about
+// all handled events and sent messages. This option can be used multiple times
+// to register more than one Observer.
+//
+// In order to observe sent messages, this option wraps the final Adapter of the
+// bot so it can be passed to joe.New(…) in any order. All durations are
+// measured with the Clock of the bot (see WithClock(…)).
+func WithObserver(o Observer) Module {
+	return ModuleFunc(func(conf *Config) error {
+		conf.observers = append(conf.observers, o)
+		return nil
+	})
+}
+
+// observeSends returns the wrap function of a wrappedAdapter that notifies all
+// observers about sent messages.
+func observeSends(observers []Observer, clock Clock) func(channel, text string, send func() error) error {
+	return func(channel, _ string, send func() error) error {
+		start := clock.Now()
+		err := send()
+		duration := clock.Now().Sub(start)
+		for _, o := range observers {
+			o.ObserveSend(channel, duration, err)
+		}
+
+		return err
+	}
+}
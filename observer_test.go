@@ -0,0 +1,95 @@
+package joe_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-joe/joe"
+	"github.com/go-joe/joe/joetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithObserver(t *testing.T) {
+	o := new(testObserver)
+	b := joetest.NewBot(t, joe.WithObserver(o))
+
+	b.Respond("ping", func(msg joe.Message) error {
+		msg.Respond("pong")
+		return nil
+	})
+
+	b.Start()
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "ping", Channel: "test"})
+	b.Stop()
+
+	assert.Equal(t, "test > pong\ntest > \n", b.ReadOutput())
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	assert.Contains(t, o.events, "joe.InitEvent")
+	assert.Contains(t, o.events, "joe.ReceiveMessageEvent")
+	assert.Contains(t, o.events, "joe.ShutdownEvent")
+	assert.Contains(t, o.handlers, "joe.ReceiveMessageEvent: github.com/go-joe/joe.(*Bot).respondRegex.func1")
+	assert.Equal(t, []string{"test"}, o.sends)
+}
+
+func TestWithObserver_AdapterModule(t *testing.T) {
+	o := new(testObserver)
+	clock := joetest.NewClock(time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC))
+	a := &slowAdapter{Adapter: joetest.NewAdapter(), clock: clock}
+
+	// The observer is passed before the Adapter module but must still observe
+	// all messages that are sent via the final Adapter.
+	b := joetest.NewBot(t, joe.WithObserver(o), joe.WithClock(clock), joe.ModuleFunc(func(conf *joe.Config) error {
+		conf.SetAdapter(a)
+		return nil
+	}))
+
+	require.NoError(t, b.Adapter.Send("Hello", "test"))
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	assert.Equal(t, []string{"test"}, o.sends)
+	assert.Equal(t, []time.Duration{2 * time.Second}, o.sendDurations, "durations should be measured with the clock of the bot")
+}
+
+// slowAdapter is an Adapter whose messages take two seconds on the given Clock.
+type slowAdapter struct {
+	joe.Adapter
+	clock *joetest.Clock
+}
+
+func (a *slowAdapter) Send(text, channel string) error {
+	a.clock.Advance(2 * time.Second)
+	return a.Adapter.Send(text, channel)
+}
+
+type testObserver struct {
+	mu            sync.Mutex
+	events        []string
+	handlers      []string
+	sends         []string
+	sendDurations []time.Duration
+}
+
+func (o *testObserver) ObserveEvent(eventType string, handlers int, duration time.Duration) {
+	o.mu.Lock()
+	o.events = append(o.events, eventType)
+	o.mu.Unlock()
+}
+
+func (o *testObserver) ObserveHandler(eventType, handler string, duration time.Duration, err error) {
+	o.mu.Lock()
+	o.handlers = append(o.handlers, eventType+": "+handler)
+	o.mu.Unlock()
+}
+
+func (o *testObserver) ObserveSend(channel string, duration time.Duration, err error) {
+	o.mu.Lock()
+	o.sends = append(o.sends, channel)
+	o.sendDurations = append(o.sendDurations, duration)
+	o.mu.Unlock()
+}
@@ -0,0 +1,80 @@
+package joe
+
+import (
+	"errors"
+	"sync"
+)
+
+// A Progress is a single message that is updated while a long running command
+// makes progress (e.g. to show a progress bar) instead of sending a new message
+// for each step. A Progress is created via Message.NewProgress(…).
+//
+// If the Adapter does not implement the EditingAdapter interface, the updates
+// are discarded and only the final text that is passed to Progress.Done(…) is
+// sent as a new message.
+type Progress struct {
+	adapter Adapter
+	editor  EditingAdapter // nil if the Adapter does not support editing
+	channel string
+	id      string // the ID of the message that is edited
+
+	mu   sync.Mutex
+	done bool
+}
+
+// NewProgress sends the initial text to the channel the message originated
+// from and returns a Progress to update this text later. If the Adapter does not
+// support editing messages, nothing is sent until Progress.Done(…) is called.
+func (msg *Message) NewProgress(initial string) (*Progress, error) {
+	p := &Progress{adapter: msg.adapter, channel: msg.Channel}
+
+	editor, ok := msg.adapter.(EditingAdapter)
+	if !ok || !AdapterSupports(msg.adapter, CapabilityEditing) {
+		return p, nil
+	}
+
+	p.editor = editor
+	id, err := p.editor.SendMessage(initial, msg.Channel)
+	if err != nil {
+		return nil, err
+	}
+
+	p.id = id
+	return p, nil
+}
+
+// Update replaces the text of the progress message. If the Adapter does not
+// support editing messages, the text is discarded.
+func (p *Progress) Update(text string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.done {
+		return errors.New("progress is already done")
+	}
+
+	if p.editor == nil {
+		return nil
+	}
+
+	return p.editor.Edit(p.channel, p.id, text)
+}
+
+// Done replaces the text of the progress message a final time. If the Adapter
+// does not support editing messages, the text is sent as a new message instead.
+// The Progress cannot be updated anymore afterwards.
+func (p *Progress) Done(text string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.done {
+		return errors.New("progress is already done")
+	}
+
+	p.done = true
+	if p.editor == nil {
+		return p.adapter.Send(text, p.channel)
+	}
+
+	return p.editor.Edit(p.channel, p.id, text)
+}
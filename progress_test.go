@@ -0,0 +1,115 @@
+package joe
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type editingMockAdapter struct {
+	MockAdapter
+}
+
+func (a *editingMockAdapter) SendMessage(text, channel string) (string, error) {
+	args := a.Called(text, channel)
+	return args.String(0), args.Error(1)
+}
+
+func (a *editingMockAdapter) Edit(channel, messageID, text string) error {
+	args := a.Called(channel, messageID, text)
+	return args.Error(0)
+}
+
+func TestMessage_NewProgress(t *testing.T) {
+	a := new(editingMockAdapter)
+	msg := Message{adapter: a, Channel: "general"}
+
+	a.On("SendMessage", "Deploying [          ]", "general").Return("1234", nil)
+	a.On("Edit", "general", "1234", "Deploying [#####     ]").Return(nil)
+	a.On("Edit", "general", "1234", "Deployed successfully").Return(nil)
+
+	p, err := msg.NewProgress("Deploying [          ]")
+	require.NoError(t, err)
+	require.NoError(t, p.Update("Deploying [#####     ]"))
+	require.NoError(t, p.Done("Deployed successfully"))
+
+	assert.EqualError(t, p.Update("Deploying again"), "progress is already done")
+	assert.EqualError(t, p.Done("Deployed again"), "progress is already done")
+	a.AssertExpectations(t)
+}
+
+func TestMessage_NewProgress_Wrapped(t *testing.T) {
+	a := new(editingMockAdapter)
+	var sent []string
+	msg := Message{Channel: "general", adapter: &wrappedAdapter{
+		Adapter: a,
+		wrap: func(_, text string, send func() error) error {
+			sent = append(sent, text)
+			return send()
+		},
+	}}
+
+	a.On("SendMessage", "Deploying", "general").Return("1234", nil)
+	a.On("Edit", "general", "1234", "Deployed").Return(nil)
+
+	p, err := msg.NewProgress("Deploying")
+	require.NoError(t, err)
+	require.NoError(t, p.Done("Deployed"))
+
+	assert.Equal(t, []string{"Deploying", "Deployed"}, sent)
+	a.AssertExpectations(t)
+}
+
+func TestMessage_NewProgress_NoEditing(t *testing.T) {
+	a := new(MockAdapter)
+	msg := Message{Channel: "general", adapter: &wrappedAdapter{
+		Adapter: a,
+		wrap: func(_, _ string, send func() error) error {
+			return send()
+		},
+	}}
+
+	p, err := msg.NewProgress("Deploying [          ]")
+	require.NoError(t, err)
+	require.NoError(t, p.Update("Deploying [#####     ]"), "updates should be discarded")
+
+	a.On("Send", "Deployed successfully", "general").Return(nil)
+	require.NoError(t, p.Done("Deployed successfully"))
+	a.AssertExpectations(t)
+}
+
+// unwrappingAdapter is a third-party wrapper that can be unwrapped but does not
+// forward the EditingAdapter interface of the wrapped Adapter.
+type unwrappingAdapter struct {
+	Adapter
+}
+
+func (a unwrappingAdapter) Unwrap() Adapter {
+	return a.Adapter
+}
+
+func TestMessage_NewProgress_UnwrapWithoutEditing(t *testing.T) {
+	a := new(editingMockAdapter)
+	msg := Message{Channel: "general", adapter: unwrappingAdapter{Adapter: a}}
+	require.True(t, AdapterSupports(msg.adapter, CapabilityEditing))
+
+	p, err := msg.NewProgress("Deploying")
+	require.NoError(t, err)
+	require.NoError(t, p.Update("Still deploying"), "updates should be discarded")
+
+	a.On("Send", "Deployed", "general").Return(nil)
+	require.NoError(t, p.Done("Deployed"))
+	a.AssertExpectations(t)
+}
+
+func TestMessage_NewProgress_Error(t *testing.T) {
+	a := new(editingMockAdapter)
+	msg := Message{adapter: a, Channel: "general"}
+
+	a.On("SendMessage", "Deploying", "general").Return("", errors.New("channel not found"))
+	p, err := msg.NewProgress("Deploying")
+	assert.EqualError(t, err, "channel not found")
+	assert.Nil(t, p)
+}
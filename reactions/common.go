@@ -0,0 +1,66 @@
+package reactions
+
+import "strings"
+
+// common contains the reactions that are recognized by most chat applications
+// (e.g. Slack, Mattermost, Rocket.Chat and Discord) under their names and some
+// aliases. Adapters may still map them to the representation of their chat
+// (e.g. by using the Raw emoji instead of the Shortcode).
+var common = map[string]Reaction{
+	"+1":                     PlusOne,
+	"-1":                     MinusOne,
+	"100":                    OneZeroZero,
+	"bulb":                   Bulb,
+	"check":                  WhiteCheckMark,
+	"clap":                   Clap,
+	"confused":               Confused,
+	"cross":                  X,
+	"cry":                    Cry,
+	"exclamation":            Exclamation,
+	"eyes":                   Eyes,
+	"fire":                   Fire,
+	"heart":                  Heart,
+	"heavy_check_mark":       HeavyCheckMark,
+	"hourglass":              Hourglass,
+	"hourglass_flowing_sand": HourglassFlowingSand,
+	"joy":                    Joy,
+	"laughing":               Laughing,
+	"muscle":                 Muscle,
+	"no_entry":               NoEntry,
+	"ok_hand":                OkHand,
+	"pray":                   Pray,
+	"question":               Question,
+	"raised_hands":           RaisedHands,
+	"rocket":                 Rocket,
+	"smile":                  Smile,
+	"tada":                   Tada,
+	"thumbsdown":             Thumbsdown,
+	"thumbsup":               Thumbsup,
+	"warning":                Warning,
+	"wave":                   Wave,
+	"white_check_mark":       WhiteCheckMark,
+	"x":                      X,
+}
+
+// ByName returns the common Reaction with the given name. The name is either
+// the shortcode of the reaction (e.g. "thumbsup" or ":thumbsup:") or one of the
+// aliases "check" and "cross". The boolean return value is false if the name
+// does not belong to a reaction that is recognized by most chat applications.
+func ByName(name string) (Reaction, bool) {
+	name = strings.ToLower(strings.Trim(name, ":"))
+	r, ok := common[name]
+	return r, ok
+}
+
+// IsCommon returns true if the given Reaction is recognized by most chat
+// applications. Reactions that are not common may only work in some chats. Use
+// Name(…) or WarnUnsupported(…) to check a Reaction for a specific Platform.
+func IsCommon(r Reaction) bool {
+	for _, c := range common {
+		if r.Shortcode == c.Shortcode {
+			return true
+		}
+	}
+
+	return false
+}
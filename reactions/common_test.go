@@ -0,0 +1,37 @@
+package reactions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestByName(t *testing.T) {
+	cases := map[string]Reaction{
+		"thumbsup":    Thumbsup,
+		":thumbsup:":  Thumbsup,
+		"+1":          PlusOne,
+		"Check":       WhiteCheckMark,
+		"cross":       X,
+		":tada:":      Tada,
+		"hourglass":   Hourglass,
+		"exclamation": Exclamation,
+	}
+
+	for name, expected := range cases {
+		r, ok := ByName(name)
+		assert.True(t, ok, name)
+		assert.Equal(t, expected, r, name)
+	}
+
+	_, ok := ByName("bowtie")
+	assert.False(t, ok)
+}
+
+func TestIsCommon(t *testing.T) {
+	assert.True(t, IsCommon(Thumbsup))
+	assert.True(t, IsCommon(WhiteCheckMark))
+	assert.True(t, IsCommon(Reaction{Shortcode: "eyes"}))
+	assert.False(t, IsCommon(Bowtie))
+	assert.False(t, IsCommon(Reaction{Shortcode: "party_parrot"}))
+}
@@ -0,0 +1,81 @@
+package reactions
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// A Platform is a chat application which may represent reactions differently
+// than others.
+type Platform string
+
+// The Platforms with a known representation of reactions.
+const (
+	Slack      Platform = "slack"      // uses the shortcodes
+	Mattermost Platform = "mattermost" // uses the shortcodes
+	RocketChat Platform = "rocketchat" // uses the shortcodes wrapped in colons
+	Discord    Platform = "discord"    // uses the unicode emoji
+	Teams      Platform = "teams"      // only supports a small fixed set of reactions
+)
+
+// teamsNames maps the shortcodes of the common reactions to the reaction types
+// of Microsoft Teams. Reactions without an entry are not supported.
+var teamsNames = map[string]string{
+	PlusOne.Shortcode:  "like",
+	Thumbsup.Shortcode: "like",
+	Heart.Shortcode:    "heart",
+	Laughing.Shortcode: "laugh",
+	Joy.Shortcode:      "laugh",
+	Smile.Shortcode:    "laugh",
+	Cry.Shortcode:      "sad",
+}
+
+// Name returns the name under which the given Platform recognizes the Reaction,
+// so Adapters do not have to maintain their own mapping. The boolean return
+// value is false if the Reaction is likely not supported by the Platform. For
+// the Platforms that use shortcodes, only the common reactions are considered
+// supported (see IsCommon(…)) since the shortcodes of the other reactions
+// differ between chat applications. For unknown Platforms the shortcode is
+// returned.
+func Name(p Platform, r Reaction) (string, bool) {
+	switch p {
+	case Discord:
+		return r.Raw, r.Raw != ""
+	case Teams:
+		name, ok := teamsNames[r.Shortcode]
+		return name, ok
+	case RocketChat:
+		return ":" + r.Shortcode + ":", IsCommon(r)
+	default:
+		return r.Shortcode, IsCommon(r)
+	}
+}
+
+// Validate returns an error if the Reaction is likely not supported by the
+// given Platform (see Name(…)).
+func Validate(p Platform, r Reaction) error {
+	if _, ok := Name(p, r); !ok {
+		return fmt.Errorf("reaction :%s: is likely not supported by %s", r.Shortcode, p)
+	}
+
+	return nil
+}
+
+// WarnUnsupported logs a warning if the Reaction is likely not supported by the
+// given Platform. It returns false in this case so bots and Adapters can decide
+// whether they still want to send the Reaction.
+func WarnUnsupported(logger *zap.Logger, p Platform, r Reaction) bool {
+	err := Validate(p, r)
+	if err == nil {
+		return true
+	}
+
+	logger.Warn("Reaction is likely not supported",
+		zap.String("reaction", r.Shortcode),
+		zap.String("platform", string(p)),
+		zap.Error(err),
+	)
+
+	return false
+}
@@ -0,0 +1,55 @@
+package reactions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestName(t *testing.T) {
+	cases := []struct {
+		platform Platform
+		reaction Reaction
+		name     string
+		ok       bool
+	}{
+		{platform: Slack, reaction: Thumbsup, name: "thumbsup", ok: true},
+		{platform: Slack, reaction: Bowtie, name: "bowtie", ok: false},
+		{platform: Mattermost, reaction: Tada, name: "tada", ok: true},
+		{platform: RocketChat, reaction: Eyes, name: ":eyes:", ok: true},
+		{platform: Discord, reaction: Thumbsup, name: "👍", ok: true},
+		{platform: Discord, reaction: Bowtie, name: "", ok: false},
+		{platform: Teams, reaction: PlusOne, name: "like", ok: true},
+		{platform: Teams, reaction: Joy, name: "laugh", ok: true},
+		{platform: Teams, reaction: Rocket, name: "", ok: false},
+		{platform: "irc", reaction: Fire, name: "fire", ok: true},
+	}
+
+	for _, c := range cases {
+		name, ok := Name(c.platform, c.reaction)
+		assert.Equal(t, c.name, name, "%s %s", c.platform, c.reaction.Shortcode)
+		assert.Equal(t, c.ok, ok, "%s %s", c.platform, c.reaction.Shortcode)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(Slack, Thumbsup))
+	assert.EqualError(t, Validate(Teams, Rocket), "reaction :rocket: is likely not supported by teams")
+}
+
+func TestWarnUnsupported(t *testing.T) {
+	core, logs := observer.New(zap.WarnLevel)
+	logger := zap.New(core)
+
+	assert.True(t, WarnUnsupported(logger, Discord, Thumbsup))
+	assert.Equal(t, 0, logs.Len())
+
+	assert.False(t, WarnUnsupported(logger, Slack, Reaction{Shortcode: "party_parrot"}))
+	entries := logs.All()
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "party_parrot", entries[0].ContextMap()["reaction"])
+		assert.Equal(t, "slack", entries[0].ContextMap()["platform"])
+	}
+}
@@ -0,0 +1,96 @@
+package joe
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// A RetryableError can be returned by an Adapter to indicate whether a failed
+// call to Adapter.Send(…) should be retried when the bot uses the
+// WithSendRetry(…) option. Event handlers that are registered with the
+// HandlerRetry(…) option can return it in the same way. Errors that do not
+// implement this interface are always retried.
+type RetryableError interface {
+	error
+	Retryable() bool
+}
+
+// WithSendRetry is an option that wraps the configured Adapter so that failed
+// calls to Adapter.Send(…) are retried up to the given number of attempts. The
+// backoff duration is doubled after each failed attempt. If all attempts fail,
+// the last error is returned. No more attempts are made if the error is a
+// RetryableError that is not retryable, if the context of the bot is done or if
+// the bot is shutting down (e.g. via Bot.Drain(…)).
+//
+// Since Adapter.Send(…) does not accept a context, the retries cannot be
+// aborted when the context of the event handler that sends the message is done
+// (e.g. because of WithHandlerTimeout(…)). Choose the attempts and backoff so
+// that all retries fit into the handler timeout of the bot.
+//
+// Like WithLoopGuard(…) this option wraps the final Adapter of the bot so it
+// can be passed to joe.New(…) in any order.
+func WithSendRetry(attempts int, backoff time.Duration) Module {
+	return ModuleFunc(func(conf *Config) error {
+		if attempts < 1 {
+			return errors.New("send retry attempts must be at least one")
+		}
+
+		conf.sendRetryAttempts = attempts
+		conf.sendRetryBackoff = backoff
+		return nil
+	})
+}
+
+// retrier retries to send messages via a wrappedAdapter.
+type retrier struct {
+	ctx      context.Context // the final context of the bot
+	closing  <-chan struct{} // closed when the Brain starts to shut down
+	logger   *zap.Logger
+	clock    Clock
+	attempts int
+	backoff  time.Duration
+}
+
+func (r *retrier) retry(channel, _ string, send func() error) error {
+	backoff := r.backoff
+	var err error
+	for i := 1; i <= r.attempts; i++ {
+		err = send()
+		if err == nil || !isRetryable(err) || i == r.attempts {
+			return err
+		}
+
+		r.logger.Info("Failed to send message, retrying",
+			zap.String("channel", channel),
+			zap.Int("attempt", i),
+			zap.Duration("backoff", backoff),
+			zap.Error(err),
+		)
+
+		timer := r.clock.NewTimer(backoff)
+		select {
+		case <-timer.C():
+			backoff *= 2
+		case <-r.ctx.Done():
+			timer.Stop()
+			return err
+		case <-r.closing:
+			timer.Stop()
+			return err
+		}
+	}
+
+	return err
+}
+
+func isRetryable(err error) bool {
+	var r RetryableError
+	if errors.As(err, &r) {
+		return r.Retryable()
+	}
+
+	return true
+}
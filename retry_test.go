@@ -0,0 +1,176 @@
+package joe
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-joe/joe/reactions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// retryTestBot creates a Bot that uses the given Adapter and context as well as
+// the passed modules.
+func retryTestBot(t *testing.T, ctx context.Context, a Adapter, modules ...Module) *Bot {
+	modules = append([]Module{
+		WithContext(ctx),
+		WithLogger(zaptest.NewLogger(t)),
+		ModuleFunc(func(conf *Config) error {
+			conf.SetAdapter(a)
+			return nil
+		}),
+	}, modules...)
+
+	b, err := NewE("test", modules...)
+	require.NoError(t, err)
+	return b
+}
+
+func TestWithSendRetry(t *testing.T) {
+	a := new(MockAdapter)
+	b := retryTestBot(t, ctx, a, WithSendRetry(3, time.Millisecond))
+
+	sendErr := errors.New("network blip")
+	a.On("Send", "Hello", "test").Return(sendErr).Twice()
+	a.On("Send", "Hello", "test").Return(nil).Once()
+
+	err := b.Adapter.Send("Hello", "test")
+	assert.NoError(t, err)
+	a.AssertExpectations(t)
+}
+
+func TestWithSendRetry_Clock(t *testing.T) {
+	a := new(MockAdapter)
+
+	// The clock is applied after the retry option and must still be used.
+	clock := new(instantClock)
+	b := retryTestBot(t, ctx, a, WithSendRetry(4, time.Hour), WithClock(clock))
+
+	sendErr := errors.New("network blip")
+	a.On("Send", "Hello", "test").Return(sendErr).Times(3)
+	a.On("Send", "Hello", "test").Return(nil).Once()
+
+	err := b.Adapter.Send("Hello", "test")
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Duration{time.Hour, 2 * time.Hour, 4 * time.Hour}, clock.waits)
+	a.AssertExpectations(t)
+}
+
+// instantClock is a Clock whose timers fire immediately but which records
+// all durations it was asked to wait for.
+type instantClock struct {
+	realClock
+	waits []time.Duration
+}
+
+func (c *instantClock) After(d time.Duration) <-chan time.Time {
+	c.waits = append(c.waits, d)
+	ch := make(chan time.Time, 1)
+	ch <- c.Now()
+	return ch
+}
+
+func (c *instantClock) NewTimer(d time.Duration) Timer {
+	c.waits = append(c.waits, d)
+	return c.realClock.NewTimer(0)
+}
+
+func TestWithSendRetry_GiveUp(t *testing.T) {
+	a := new(MockAdapter)
+	b := retryTestBot(t, ctx, a, WithSendRetry(3, time.Millisecond))
+
+	sendErr := errors.New("network blip")
+	a.On("Send", "Hello", "test").Return(sendErr).Times(3)
+
+	err := b.Adapter.Send("Hello", "test")
+	assert.Equal(t, sendErr, err)
+	a.AssertExpectations(t)
+}
+
+func TestWithSendRetry_PermanentError(t *testing.T) {
+	a := new(MockAdapter)
+	b := retryTestBot(t, ctx, a, WithSendRetry(3, time.Millisecond))
+
+	sendErr := retryableError{retryable: false}
+	a.On("Send", "Hello", "test").Return(sendErr).Once()
+
+	err := b.Adapter.Send("Hello", "test")
+	assert.Equal(t, sendErr, err)
+	a.AssertExpectations(t)
+}
+
+func TestWithSendRetry_ContextCanceled(t *testing.T) {
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// The context is passed after the retry option and must still be used.
+	a := new(MockAdapter)
+	b := retryTestBot(t, ctx, a, WithSendRetry(3, time.Hour), WithContext(canceledCtx))
+
+	sendErr := errors.New("network blip")
+	a.On("Send", "Hello", "test").Return(sendErr).Once()
+
+	err := b.Adapter.Send("Hello", "test")
+	assert.Equal(t, sendErr, err)
+	a.AssertExpectations(t)
+}
+
+func TestWithSendRetry_Shutdown(t *testing.T) {
+	a := new(MockAdapter)
+	b := retryTestBot(t, ctx, a, WithSendRetry(3, time.Hour))
+	b.Brain.Shutdown(ctx)
+
+	sendErr := errors.New("network blip")
+	a.On("Send", "Hello", "test").Return(sendErr).Once()
+
+	err := b.Adapter.Send("Hello", "test")
+	assert.Equal(t, sendErr, err, "should not retry while the bot is shutting down")
+	a.AssertExpectations(t)
+}
+
+func TestWithSendRetry_InvalidAttempts(t *testing.T) {
+	_, err := NewE("test", WithContext(ctx), WithSendRetry(0, time.Millisecond))
+	assert.EqualError(t, err, "failed to initialize bot: send retry attempts must be at least one")
+}
+
+func TestWithSendRetry_OptionalInterfaces(t *testing.T) {
+	a := new(MockAdapter)
+	b := retryTestBot(t, ctx, a, WithSendRetry(2, time.Millisecond))
+
+	wrapped := b.Adapter
+	err := wrapped.(ReactionAwareAdapter).React(reactions.Thumbsup, Message{})
+	assert.Equal(t, ErrNotImplemented, err)
+	assert.Equal(t, "", wrapped.(SelfAwareAdapter).BotUserID())
+
+	a.On("Send", "Hello", "test").Return(nil).Once()
+	err = wrapped.(EphemeralAdapter).SendEphemeral("test", "alice", "Hello")
+	assert.NoError(t, err, "ephemeral messages should fall back to Send")
+	a.AssertExpectations(t)
+
+	ea := new(ExtendedMockAdapter)
+	b = retryTestBot(t, ctx, ea, WithSendRetry(2, time.Millisecond))
+
+	wrapped = b.Adapter
+	ea.On("React", reactions.Thumbsup, Message{}).Return(nil)
+	ea.On("SendEphemeral", "test", "alice", "Hello").Return(errors.New("blip")).Once()
+	ea.On("SendEphemeral", "test", "alice", "Hello").Return(nil).Once()
+
+	assert.NoError(t, wrapped.(ReactionAwareAdapter).React(reactions.Thumbsup, Message{}))
+	assert.NoError(t, wrapped.(EphemeralAdapter).SendEphemeral("test", "alice", "Hello"))
+	ea.AssertExpectations(t)
+}
+
+type retryableError struct {
+	retryable bool
+}
+
+func (e retryableError) Error() string {
+	return "retryable error"
+}
+
+func (e retryableError) Retryable() bool {
+	return e.retryable
+}
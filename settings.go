@@ -0,0 +1,60 @@
+package joe
+
+import "strings"
+
+// settingsKeyPrefix is the key prefix in the Storage that all user settings have.
+const settingsKeyPrefix = "joe.settings."
+
+// UserSettings provides access to the settings of a single user (e.g. the
+// timezone or whether the user wants to receive notifications). The settings
+// are stored in the Storage of the bot under keys that are namespaced by the
+// (escaped) user ID so the same setting can be used for all users. Values are encoded
+// with the MemoryEncoder of the Storage, so any value that can be stored via
+// Storage.Set(…) can also be used as a setting.
+type UserSettings struct {
+	store  *Storage
+	prefix string
+}
+
+// UserSettings returns the settings of the user with the given ID.
+func (b *Bot) UserSettings(userID string) *UserSettings {
+	return &UserSettings{
+		store:  b.Store,
+		prefix: settingsKeyPrefix + keySegmentEscaper.Replace(userID) + ".",
+	}
+}
+
+// Get retrieves the setting under the given key and decodes it into the passed
+// value which must be a pointer. The boolean return value indicates if the
+// user has this setting. See Storage.Get(…) for details.
+func (s *UserSettings) Get(key string, value interface{}) (bool, error) {
+	return s.store.Get(s.prefix+key, value)
+}
+
+// Set stores the given value as setting under the given key.
+func (s *UserSettings) Set(key string, value interface{}) error {
+	return s.store.Set(s.prefix+key, value)
+}
+
+// Delete removes the setting under the given key. The boolean return value
+// indicates if the user had this setting.
+func (s *UserSettings) Delete(key string) (bool, error) {
+	return s.store.Delete(s.prefix + key)
+}
+
+// Keys returns the keys of all settings of the user in alphabetical order.
+func (s *UserSettings) Keys() ([]string, error) {
+	keys, err := s.store.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	var settings []string
+	for _, key := range keys {
+		if strings.HasPrefix(key, s.prefix) {
+			settings = append(settings, strings.TrimPrefix(key, s.prefix))
+		}
+	}
+
+	return settings, nil
+}
@@ -0,0 +1,69 @@
+package joe_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-joe/joe/joetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserSettings(t *testing.T) {
+	b := joetest.NewBot(t)
+	alice := b.UserSettings("alice")
+	bob := b.UserSettings("bob")
+
+	var timezone string
+	ok, err := alice.Get("timezone", &timezone)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, alice.Set("timezone", "Europe/Berlin"))
+	require.NoError(t, alice.Set("notifications", true))
+	require.NoError(t, bob.Set("reminder", 15*time.Minute))
+
+	ok, err = alice.Get("timezone", &timezone)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "Europe/Berlin", timezone)
+
+	var reminder time.Duration
+	ok, err = bob.Get("reminder", &reminder)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 15*time.Minute, reminder)
+
+	ok, err = bob.Get("timezone", nil)
+	require.NoError(t, err)
+	assert.False(t, ok, "settings of other users should not be visible")
+
+	keys, err := alice.Keys()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"notifications", "timezone"}, keys)
+
+	ok, err = alice.Delete("timezone")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	keys, err = alice.Keys()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"notifications"}, keys)
+}
+
+func TestUserSettings_DotInUserID(t *testing.T) {
+	b := joetest.NewBot(t)
+	a := b.UserSettings("a")
+	ab := b.UserSettings("a.b")
+
+	require.NoError(t, a.Set("timezone", "UTC"))
+	require.NoError(t, ab.Set("reminder", true))
+
+	keys, err := a.Keys()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"timezone"}, keys)
+
+	keys, err = ab.Keys()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"reminder"}, keys)
+}
@@ -0,0 +1,62 @@
+// Package status implements a joe.Module that lets users ask the bot for its
+// version and uptime.
+package status
+
+import (
+	"time"
+
+	"github.com/go-joe/joe"
+)
+
+// Module returns a joe.Module that registers the "version" and "uptime"
+// commands. The version is typically injected at build time via ldflags:
+//
+//   var version = "dev" // set via -ldflags "-X main.version=v1.2.3"
+//
+//   b := joe.New("example", status.Module(version))
+//
+// The uptime is measured from the moment the bot handles the joe.InitEvent.
+func Module(version string) joe.Module {
+	return joe.ModuleFunc(func(conf *joe.Config) error {
+		s := &status{
+			version: version,
+			conf:    conf,
+		}
+
+		conf.RegisterHandler(s.setup)
+		conf.RegisterHandler(s.init)
+		conf.Respond("version", s.versionCommand)
+		conf.Respond("uptime", s.uptimeCommand)
+		return nil
+	})
+}
+
+type status struct {
+	version string
+	name    string
+	clock   joe.Clock
+	conf    *joe.Config // only used until the setup is finished
+	started time.Time
+}
+
+// setup resolves the final name and Clock of the bot. Other modules (e.g.
+// joe.WithClock(…)) may change them after this module was applied, so we can
+// only look them up once all modules are ready.
+func (s *status) setup(joe.ModulesReadyEvent) {
+	s.name = s.conf.Name
+	s.clock = s.conf.Clock()
+	s.conf = nil
+}
+
+func (s *status) init(joe.InitEvent) {
+	s.started = s.clock.Now()
+}
+
+func (s *status) versionCommand(msg joe.Message) error {
+	return msg.RespondE("%s version %s", s.name, s.version)
+}
+
+func (s *status) uptimeCommand(msg joe.Message) error {
+	uptime := s.clock.Now().Sub(s.started).Round(time.Second)
+	return msg.RespondE("%s is up for %s (since %s)", s.name, uptime, s.started.Format(time.RFC3339))
+}
@@ -0,0 +1,75 @@
+package status
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-joe/joe"
+	"github.com/go-joe/joe/joetest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModule(t *testing.T) {
+	b := joetest.NewBot(t, Module("v1.2.3"))
+
+	var defaultHandlerExecuted bool
+	b.Respond(".*", func(joe.Message) error {
+		defaultHandlerExecuted = true
+		return nil
+	})
+
+	b.Start()
+	defer b.Stop()
+	assert.Equal(t, "test > ", b.ReadOutput())
+
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "Version"})
+	assert.Equal(t, "test version v1.2.3\n", b.ReadOutput())
+	assert.False(t, defaultHandlerExecuted)
+
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "uptime"})
+	assert.Regexp(t, `^test is up for \d+s \(since .+\)\n$`, b.ReadOutput())
+	assert.False(t, defaultHandlerExecuted)
+
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "something else"})
+	assert.True(t, defaultHandlerExecuted)
+}
+
+func TestModule_Uptime(t *testing.T) {
+	clock := joetest.NewClock(time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC))
+	b := joetest.NewBot(t, Module("v1.2.3"), joe.WithClock(clock))
+
+	b.Start()
+	defer b.Stop()
+	assert.Equal(t, "test > ", b.ReadOutput())
+
+	clock.Advance(90*time.Minute + 1500*time.Millisecond)
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "UPTIME"})
+	assert.Equal(t, "test is up for 1h30m2s (since 2020-01-01T12:00:00Z)\n", b.ReadOutput())
+}
+
+func TestModule_Name(t *testing.T) {
+	// The name is changed after the module was applied and must still be used.
+	rename := joe.ModuleFunc(func(conf *joe.Config) error {
+		conf.Name = "renamed"
+		return nil
+	})
+
+	b := joetest.NewBot(t, Module("v1.2.3"), rename)
+	b.Start()
+	defer b.Stop()
+	assert.Equal(t, "test > ", b.ReadOutput())
+
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "version"})
+	assert.Equal(t, "renamed version v1.2.3\n", b.ReadOutput())
+}
+
+func TestModule_IgnoreBots(t *testing.T) {
+	b := joetest.NewBot(t, Module("v1.2.3"), joe.WithIgnoreBots())
+
+	b.Start()
+	defer b.Stop()
+	assert.Equal(t, "test > ", b.ReadOutput())
+
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "version", AuthorIsBot: true})
+	assert.Empty(t, b.ReadOutput(), "the commands should not respond to other bots")
+}
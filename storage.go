@@ -1,10 +1,17 @@
 package joe
 
 import (
+	"bytes"
+	"container/list"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"reflect"
 	"sort"
 	"sync"
+	"sync/atomic"
 
 	"go.uber.org/zap"
 )
@@ -20,6 +27,13 @@ type Storage struct {
 	mu      sync.RWMutex
 	memory  Memory
 	encoder MemoryEncoder
+
+	lastKnownMu  sync.Mutex
+	lastKnown    map[string]*list.Element // see Storage.GetWithFallback(…)
+	lastKnownLRU *list.List               // values are *lastKnownValue, most recently read first
+	lastKnownGen uint64                   // incremented whenever a key is modified
+
+	indexState int32 // accessed atomically, see Storage.indexUsed(…)
 }
 
 // The Memory interface allows the bot to persist data as key-value pairs.
@@ -34,6 +48,36 @@ type Memory interface {
 	Close() error
 }
 
+// CASMemory is an optional interface that a Memory can implement if it supports
+// an atomic compare-and-swap operation (e.g. via WATCH/MULTI in redis). The
+// swap must only happen if the current value under the key equals the old
+// value. A nil old value means that the key must not exist yet. The returned
+// boolean indicates if the value was swapped.
+type CASMemory interface {
+	Memory
+	CompareAndSwap(key string, old, new []byte) (bool, error)
+}
+
+// ContextMemory is an optional interface that a Memory can implement if its
+// operations can be canceled via a context (e.g. because it connects to redis).
+// If the Memory implements this interface, the context that is passed to
+// Storage.GetCtx(…), Storage.SetCtx(…) and Storage.DeleteCtx(…) is passed
+// through so handler timeouts also cancel the I/O a handler is waiting on.
+type ContextMemory interface {
+	Memory
+	GetCtx(ctx context.Context, key string) ([]byte, bool, error)
+	SetCtx(ctx context.Context, key string, value []byte) error
+	DeleteCtx(ctx context.Context, key string) (bool, error)
+}
+
+// PingMemory is an optional interface that a Memory can implement if it
+// connects to an external backend and is able to check if that backend is
+// currently reachable.
+type PingMemory interface {
+	Memory
+	Ping() error
+}
+
 // A MemoryEncoder is used to encode and decode any values that are stored in
 // the Memory. The default implementation that is used by the Storage uses a
 // JSON encoding.
@@ -42,8 +86,21 @@ type MemoryEncoder interface {
 	Decode(data []byte, target interface{}) error
 }
 
+// inMemory is the default Memory implementation. It optionally limits the
+// number of entries and their approximate size by evicting the least recently
+// used entries.
 type inMemory struct {
-	data map[string][]byte
+	mu         sync.Mutex // protects the LRU list which is modified on reads
+	data       map[string]*list.Element
+	lru        *list.List // values are *inMemoryEntry, most recently used first
+	size       int        // approximate size of all keys and values in bytes
+	maxEntries int        // zero means no limit
+	maxBytes   int        // zero means no limit
+}
+
+type inMemoryEntry struct {
+	key   string
+	value []byte
 }
 
 type jsonEncoder struct{}
@@ -64,6 +121,13 @@ func (s *Storage) SetMemory(m Memory) {
 	s.mu.Lock()
 	s.memory = m
 	s.mu.Unlock()
+	atomic.StoreInt32(&s.indexState, indexStateUnknown)
+
+	s.lastKnownMu.Lock()
+	s.lastKnown = nil
+	s.lastKnownLRU = nil
+	s.lastKnownGen++
+	s.lastKnownMu.Unlock()
 }
 
 // SetMemoryEncoder assigns a different MemoryEncoder.
@@ -86,28 +150,80 @@ func (s *Storage) Keys() ([]string, error) {
 // Set encodes the given data and stores it in the Memory that is managed by the
 // Storage.
 func (s *Storage) Set(key string, value interface{}) error {
-	data, err := s.encoder.Encode(value)
+	return s.SetCtx(context.Background(), key, value)
+}
+
+// SetCtx is like Storage.Set(…) but the operation is canceled when the given
+// context is done. If the Memory does not implement the ContextMemory interface,
+// the context is only checked before the value is written.
+func (s *Storage) SetCtx(ctx context.Context, key string, value interface{}) error {
+	return s.set(ctx, key, value, s.encoder)
+}
+
+// SetWith is like Storage.Set(…) but the value is encoded with the given
+// MemoryEncoder instead of the MemoryEncoder of the Storage. This lets you use
+// a different encoding for some keys only (e.g. to compress large values). The
+// value must be read via Storage.GetWith(…) using the same MemoryEncoder. If
+// enc is nil, the MemoryEncoder of the Storage is used.
+func (s *Storage) SetWith(key string, value interface{}, enc MemoryEncoder) error {
+	if enc == nil {
+		enc = s.encoder
+	}
+
+	return s.set(context.Background(), key, value, enc)
+}
+
+func (s *Storage) set(ctx context.Context, key string, value interface{}, enc MemoryEncoder) error {
+	data, err := enc.Encode(value)
 	if err != nil {
 		return fmt.Errorf("encode data: %w", err)
 	}
 
 	s.mu.Lock()
 	s.logger.Debug("Writing data to memory", zap.String("key", key))
-	err = s.memory.Set(key, data)
+	err = memorySet(ctx, s.memory, key, data)
 	s.mu.Unlock()
+	s.forgetLastKnown(key)
 
 	return err
 }
 
 // Get retrieves the value under the requested key and decodes it into the
-// passed "value" argument which must be a pointer. The boolean return value
-// indicates if the value actually existed in the Memory and is false if it did
-// not. It is legal to pass <nil> as the value if you only want to check if
-// the given key exists but you do not actually care about the concrete value.
+// passed "value" argument which must be a pointer. The boolean return value is
+// true only if the key existed in the Memory and its value was decoded
+// successfully. If the key does not exist, false and no error is returned and
+// the value is left untouched. If the value cannot be decoded, false and the
+// decoding error are returned.
+//
+// It is legal to pass <nil> as the value if you only want to check if the given
+// key exists but you do not actually care about the concrete value. In this
+// case nothing is decoded. Storage.Has(…) does the same but is more explicit.
 func (s *Storage) Get(key string, value interface{}) (bool, error) {
+	return s.GetCtx(context.Background(), key, value)
+}
+
+// GetCtx is like Storage.Get(…) but the operation is canceled when the given
+// context is done. If the Memory does not implement the ContextMemory interface,
+// the context is only checked before the value is read.
+func (s *Storage) GetCtx(ctx context.Context, key string, value interface{}) (bool, error) {
+	return s.get(ctx, key, value, s.encoder)
+}
+
+// GetWith is like Storage.Get(…) but the value is decoded with the given
+// MemoryEncoder instead of the MemoryEncoder of the Storage. See
+// Storage.SetWith(…).
+func (s *Storage) GetWith(key string, value interface{}, enc MemoryEncoder) (bool, error) {
+	if enc == nil {
+		enc = s.encoder
+	}
+
+	return s.get(context.Background(), key, value, enc)
+}
+
+func (s *Storage) get(ctx context.Context, key string, value interface{}, enc MemoryEncoder) (bool, error) {
 	s.mu.RLock()
 	s.logger.Debug("Retrieving data from memory", zap.String("key", key))
-	data, ok, err := s.memory.Get(key)
+	data, ok, err := memoryGet(ctx, s.memory, key)
 	s.mu.RUnlock()
 	if err != nil {
 		return false, err
@@ -117,7 +233,7 @@ func (s *Storage) Get(key string, value interface{}) (bool, error) {
 		return ok, nil
 	}
 
-	err = s.encoder.Decode(data, value)
+	err = enc.Decode(data, value)
 	if err != nil {
 		return false, fmt.Errorf("decode data: %w", err)
 	}
@@ -125,17 +241,432 @@ func (s *Storage) Get(key string, value interface{}) (bool, error) {
 	return true, nil
 }
 
+// Has returns true if the given key exists in the Memory. Other than
+// Storage.Get(…) it never decodes the value.
+func (s *Storage) Has(key string) (bool, error) {
+	return s.Get(key, nil)
+}
+
+// CompareAndSwap atomically replaces the value under the given key with the new
+// value but only if the current value equals the old value. The values are
+// compared in their encoded form. If old is nil, the new value is only stored
+// if the key does not exist yet. The boolean return value indicates if the
+// value was actually swapped.
+//
+// If the Memory implements the CASMemory interface, the swap is delegated to it
+// which allows backends such as redis to guarantee atomicity even across
+// multiple processes. Otherwise the swap is only atomic with regards to other
+// operations on this Storage.
+func (s *Storage) CompareAndSwap(key string, old, new interface{}) (bool, error) {
+	var oldData []byte
+	if old != nil {
+		var err error
+		oldData, err = s.encoder.Encode(old)
+		if err != nil {
+			return false, fmt.Errorf("encode old data: %w", err)
+		}
+	}
+
+	newData, err := s.encoder.Encode(new)
+	if err != nil {
+		return false, fmt.Errorf("encode new data: %w", err)
+	}
+
+	defer s.forgetLastKnown(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.logger.Debug("Swapping data in memory", zap.String("key", key))
+	if m, ok := s.memory.(CASMemory); ok {
+		return m.CompareAndSwap(key, oldData, newData)
+	}
+
+	return compareAndSwap(s.memory, key, oldData, newData)
+}
+
+// maxUpdateAttempts is the number of times Storage.Update(…) retries to store
+// a value if it was concurrently modified in a CASMemory.
+const maxUpdateAttempts = 10
+
+// Update atomically loads the value under the given key into ptr, which must
+// be a pointer, then calls mutate and finally stores the modified value of ptr
+// back under the same key. If the key does not exist, ptr is set to its zero
+// value before mutate is called. If mutate returns an error, nothing is stored
+// and the error is returned.
+//
+// The whole operation happens while holding the lock of the Storage so the
+// update is atomic for the default in-memory backend. If the Memory implements
+// the CASMemory interface, the value is stored via compare-and-swap and the
+// update is retried (including calling mutate again) if the value was modified
+// concurrently by another process. If the value could not be stored after
+// several attempts, the returned error wraps ErrConcurrentModification. Since
+// the lock is held, mutate must not access the Storage itself.
+func (s *Storage) Update(key string, ptr interface{}, mutate func() error) error {
+	val := reflect.ValueOf(ptr)
+	if val.Kind() != reflect.Ptr || val.IsNil() {
+		return errors.New("update target must be a non-nil pointer")
+	}
+
+	defer s.forgetLastKnown(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.logger.Debug("Updating data in memory", zap.String("key", key))
+	for i := 0; i < maxUpdateAttempts; i++ {
+		oldData, ok, err := s.memory.Get(key)
+		if err != nil {
+			return err
+		}
+
+		val.Elem().Set(reflect.Zero(val.Elem().Type()))
+		switch {
+		case !ok:
+			oldData = nil // mark key as absent for the compare-and-swap
+		case oldData == nil:
+			oldData = []byte{} // key exists but its value is empty
+		default:
+			err = s.encoder.Decode(oldData, ptr)
+			if err != nil {
+				return fmt.Errorf("decode data: %w", err)
+			}
+		}
+
+		err = mutate()
+		if err != nil {
+			return err
+		}
+
+		newData, err := s.encoder.Encode(ptr)
+		if err != nil {
+			return fmt.Errorf("encode data: %w", err)
+		}
+
+		m, ok := s.memory.(CASMemory)
+		if !ok {
+			return s.memory.Set(key, newData)
+		}
+
+		swapped, err := m.CompareAndSwap(key, oldData, newData)
+		if err != nil || swapped {
+			return err
+		}
+
+		s.logger.Debug("Retrying update after concurrent modification", zap.String("key", key))
+	}
+
+	return fmt.Errorf("failed to update key %q after %d attempts: %w", key, maxUpdateAttempts, ErrConcurrentModification)
+}
+
+// compareAndSwap implements a compare-and-swap operation on any Memory. The
+// caller must ensure that the Memory is not accessed concurrently.
+func compareAndSwap(m Memory, key string, old, new []byte) (bool, error) {
+	current, ok, err := m.Get(key)
+	if err != nil {
+		return false, err
+	}
+
+	switch {
+	case old == nil && ok:
+		return false, nil
+	case old != nil && (!ok || !bytes.Equal(current, old)):
+		return false, nil
+	}
+
+	err = m.Set(key, new)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
 // Delete removes a key and its associated value from the memory. The boolean
 // return value indicates if the key existed or not.
+//
+// If the key was added to any index via Storage.Index(…), it is also removed
+// from these indexes. To do so, Delete reads the index values of the key from
+// the Memory but only if any key was indexed so far.
 func (s *Storage) Delete(key string) (bool, error) {
+	return s.DeleteCtx(context.Background(), key)
+}
+
+// DeleteCtx is like Storage.Delete(…) but deleting the key is canceled when the
+// given context is done. If the Memory does not implement the ContextMemory
+// interface, the context is only checked before the key is deleted.
+func (s *Storage) DeleteCtx(ctx context.Context, key string) (bool, error) {
+	ok, err := s.delete(ctx, key)
+	if err != nil || isIndexKey(key) {
+		return ok, err
+	}
+
+	err = s.unindexAll(ctx, key)
+	if err != nil {
+		return ok, fmt.Errorf("failed to remove key from indexes: %w", err)
+	}
+
+	return ok, nil
+}
+
+func (s *Storage) delete(ctx context.Context, key string) (bool, error) {
 	s.mu.Lock()
 	s.logger.Debug("Deleting data from memory", zap.String("key", key))
-	ok, err := s.memory.Delete(key)
+	ok, err := memoryDelete(ctx, s.memory, key)
 	s.mu.Unlock()
+	s.forgetLastKnown(key)
 
 	return ok, err
 }
 
+// memoryGet reads the key from the Memory and passes the context through if the
+// Memory implements the ContextMemory interface.
+func memoryGet(ctx context.Context, m Memory, key string) ([]byte, bool, error) {
+	if cm, ok := m.(ContextMemory); ok {
+		return cm.GetCtx(ctx, key)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+
+	return m.Get(key)
+}
+
+// memorySet is like memoryGet but for Memory.Set(…).
+func memorySet(ctx context.Context, m Memory, key string, value []byte) error {
+	if cm, ok := m.(ContextMemory); ok {
+		return cm.SetCtx(ctx, key, value)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return m.Set(key, value)
+}
+
+// memoryDelete is like memoryGet but for Memory.Delete(…).
+func memoryDelete(ctx context.Context, m Memory, key string) (bool, error) {
+	if cm, ok := m.(ContextMemory); ok {
+		return cm.DeleteCtx(ctx, key)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	return m.Delete(key)
+}
+
+// Ping checks if the Memory is currently available. If the Memory does not
+// implement the PingMemory interface it is assumed to be always available.
+// Any returned error matches ErrMemoryUnavailable when using errors.Is(…).
+func (s *Storage) Ping() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	m, ok := s.memory.(PingMemory)
+	if !ok {
+		return nil
+	}
+
+	err := m.Ping()
+	if err == nil || errors.Is(err, ErrMemoryUnavailable) {
+		return err
+	}
+
+	return memoryUnavailableError{err: err}
+}
+
+// memoryUnavailableError wraps an error of a Memory so it matches both the
+// original error and ErrMemoryUnavailable.
+type memoryUnavailableError struct {
+	err error
+}
+
+func (e memoryUnavailableError) Error() string {
+	return fmt.Sprintf("%s: %v", ErrMemoryUnavailable, e.err)
+}
+
+func (e memoryUnavailableError) Unwrap() error {
+	return e.err
+}
+
+func (e memoryUnavailableError) Is(target error) bool {
+	return target == ErrMemoryUnavailable
+}
+
+// exportEntry is a single line written by Storage.Export(…). The raw value is
+// encoded as base64 so binary values survive the JSON encoding.
+type exportEntry struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// Export writes all key value pairs of the Memory to w as JSON lines, one
+// object with a "key" and a base64 encoded "value" per line. The values are
+// exported as they are stored in the Memory, i.e. without decoding them via
+// the MemoryEncoder. Values are read and written one at a time so large
+// datasets do not need to fit into memory. Keys that are deleted while the
+// export is running are skipped.
+//
+// The output can be loaded into a Storage with another Memory implementation
+// via Storage.Import(…) which makes it possible to backup the bot or to migrate
+// between memory backends.
+func (s *Storage) Export(w io.Writer) error {
+	keys, err := s.Keys()
+	if err != nil {
+		return fmt.Errorf("failed to list keys: %w", err)
+	}
+
+	enc := json.NewEncoder(w)
+	for _, key := range keys {
+		s.mu.RLock()
+		value, ok, err := s.memory.Get(key)
+		s.mu.RUnlock()
+		if err != nil {
+			return fmt.Errorf("failed to get key %q: %w", key, err)
+		}
+		if !ok {
+			continue
+		}
+
+		err = enc.Encode(exportEntry{Key: key, Value: value})
+		if err != nil {
+			return fmt.Errorf("failed to export key %q: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// Import reads key value pairs in the format of Storage.Export(…) from r and
+// stores them in the Memory. Existing keys are overwritten while all other
+// keys of the Memory are left untouched. Entries are read one at a time so
+// large datasets do not need to fit into memory.
+func (s *Storage) Import(r io.Reader) error {
+	dec := json.NewDecoder(r)
+	for n := 1; ; n++ {
+		var entry exportEntry
+		err := dec.Decode(&entry)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to decode entry %d: %w", n, err)
+		}
+
+		if entry.Key == "" {
+			return fmt.Errorf("entry %d has no key", n)
+		}
+
+		s.mu.Lock()
+		s.logger.Debug("Importing data to memory", zap.String("key", entry.Key))
+		err = s.memory.Set(entry.Key, entry.Value)
+		s.mu.Unlock()
+		s.forgetLastKnown(entry.Key)
+		if err != nil {
+			return fmt.Errorf("failed to import key %q: %w", entry.Key, err)
+		}
+	}
+}
+
+// A MigrateOption can be passed to MigrateMemory(…) to change how keys are
+// copied.
+type MigrateOption func(*migrateOptions)
+
+type migrateOptions struct {
+	overwrite bool
+	logger    *zap.Logger
+}
+
+// MigrateOverwrite is a MigrateOption to overwrite keys that already exist in
+// the destination Memory. By default existing keys are skipped.
+func MigrateOverwrite() MigrateOption {
+	return func(opts *migrateOptions) {
+		opts.overwrite = true
+	}
+}
+
+// MigrateLogger is a MigrateOption to report the progress of the migration via
+// the given logger. By default nothing is logged.
+func MigrateLogger(logger *zap.Logger) MigrateOption {
+	return func(opts *migrateOptions) {
+		opts.logger = logger
+	}
+}
+
+// migrateProgressInterval is the number of keys after which MigrateMemory(…)
+// logs its progress.
+const migrateProgressInterval = 1000
+
+// MigrateMemory copies all keys and values from the src Memory to the dst
+// Memory. Keys that already exist in dst are skipped unless the
+// MigrateOverwrite() option is passed, so the migration can safely be executed
+// more than once (e.g. each time the bot starts). Keys in dst that do not exist
+// in src are left untouched.
+//
+// This function is useful to switch a bot to another Memory implementation
+// without losing its data. Note that the Memory implementations are not closed
+// by this function.
+func MigrateMemory(src, dst Memory, opts ...MigrateOption) error {
+	options := migrateOptions{logger: zap.NewNop()}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	keys, err := src.Keys()
+	if err != nil {
+		return fmt.Errorf("failed to list keys: %w", err)
+	}
+
+	sort.Strings(keys)
+	options.logger.Info("Migrating memory", zap.Int("keys", len(keys)))
+
+	var copied, skipped int
+	for i, key := range keys {
+		if i > 0 && i%migrateProgressInterval == 0 {
+			options.logger.Info("Migrating memory",
+				zap.Int("done", i),
+				zap.Int("keys", len(keys)),
+			)
+		}
+
+		if !options.overwrite {
+			_, exists, err := dst.Get(key)
+			if err != nil {
+				return fmt.Errorf("failed to get key %q from destination: %w", key, err)
+			}
+			if exists {
+				skipped++
+				continue
+			}
+		}
+
+		value, ok, err := src.Get(key)
+		if err != nil {
+			return fmt.Errorf("failed to get key %q: %w", key, err)
+		}
+		if !ok {
+			// The key was deleted after we listed all keys.
+			continue
+		}
+
+		err = dst.Set(key, value)
+		if err != nil {
+			return fmt.Errorf("failed to set key %q: %w", key, err)
+		}
+
+		copied++
+	}
+
+	options.logger.Info("Memory migration completed",
+		zap.Int("copied", copied),
+		zap.Int("skipped", skipped),
+	)
+
+	return nil
+}
+
 // Close closes the Memory that is managed by this Storage.
 func (s *Storage) Close() error {
 	s.mu.Lock()
@@ -145,26 +676,90 @@ func (s *Storage) Close() error {
 }
 
 func newInMemory() *inMemory {
-	return &inMemory{data: map[string][]byte{}}
+	return &inMemory{
+		data: map[string]*list.Element{},
+		lru:  list.New(),
+	}
 }
 
 func (m *inMemory) Set(key string, value []byte) error {
-	m.data[key] = value
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	size := len(key) + len(value)
+	if m.maxBytes > 0 && size > m.maxBytes {
+		return fmt.Errorf("value of key %q exceeds memory limit of %d bytes", key, m.maxBytes)
+	}
+
+	if elem, ok := m.data[key]; ok {
+		entry := elem.Value.(*inMemoryEntry)
+		m.size += len(value) - len(entry.value)
+		entry.value = value
+		m.lru.MoveToFront(elem)
+	} else {
+		m.data[key] = m.lru.PushFront(&inMemoryEntry{key: key, value: value})
+		m.size += size
+	}
+
+	m.evict()
 	return nil
 }
 
+// evict removes the least recently used entries until the memory is within its
+// configured limits. The caller must hold the lock.
+func (m *inMemory) evict() {
+	for m.lru.Len() > 0 {
+		tooManyEntries := m.maxEntries > 0 && m.lru.Len() > m.maxEntries
+		tooManyBytes := m.maxBytes > 0 && m.size > m.maxBytes
+		if !tooManyEntries && !tooManyBytes {
+			return
+		}
+
+		m.remove(m.lru.Back())
+	}
+}
+
+// remove deletes the given element from the memory. The caller must hold the
+// lock.
+func (m *inMemory) remove(elem *list.Element) {
+	entry := m.lru.Remove(elem).(*inMemoryEntry)
+	delete(m.data, entry.key)
+	m.size -= len(entry.key) + len(entry.value)
+}
+
 func (m *inMemory) Get(key string) ([]byte, bool, error) {
-	value, ok := m.data[key]
-	return value, ok, nil
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	elem, ok := m.data[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	m.lru.MoveToFront(elem)
+	return elem.Value.(*inMemoryEntry).value, true, nil
 }
 
 func (m *inMemory) Delete(key string) (bool, error) {
-	_, ok := m.data[key]
-	delete(m.data, key)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	elem, ok := m.data[key]
+	if ok {
+		m.remove(elem)
+	}
+
 	return ok, nil
 }
 
+func (m *inMemory) CompareAndSwap(key string, old, new []byte) (bool, error) {
+	return compareAndSwap(m, key, old, new)
+}
+
 func (m *inMemory) Keys() ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	keys := make([]string, 0, len(m.data))
 	for k := range m.data {
 		keys = append(keys, k)
@@ -174,7 +769,11 @@ func (m *inMemory) Keys() ([]string, error) {
 }
 
 func (m *inMemory) Close() error {
-	m.data = map[string][]byte{}
+	m.mu.Lock()
+	m.data = map[string]*list.Element{}
+	m.lru.Init()
+	m.size = 0
+	m.mu.Unlock()
 	return nil
 }
 
@@ -2,13 +2,19 @@ package joe
 
 import (
 	"bytes"
+	"context"
 	"encoding/gob"
 	"errors"
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestStorage(t *testing.T) {
@@ -57,6 +63,320 @@ func TestStorage(t *testing.T) {
 	assert.NoError(t, store.Close())
 }
 
+func TestStorage_CompareAndSwap(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	store := NewStorage(logger)
+
+	ok, err := store.CompareAndSwap("test", "foo", "bar")
+	assert.NoError(t, err)
+	assert.False(t, ok, "should not swap a key that does not exist")
+
+	ok, err = store.CompareAndSwap("test", nil, "foo")
+	assert.NoError(t, err)
+	assert.True(t, ok, "should swap a key that does not exist if old value is nil")
+
+	ok, err = store.CompareAndSwap("test", nil, "bar")
+	assert.NoError(t, err)
+	assert.False(t, ok, "should not swap an existing key if old value is nil")
+
+	ok, err = store.CompareAndSwap("test", "baz", "bar")
+	assert.NoError(t, err)
+	assert.False(t, ok, "should not swap if old value does not match")
+
+	ok, err = store.CompareAndSwap("test", "foo", "bar")
+	assert.NoError(t, err)
+	assert.True(t, ok, "should swap if old value matches")
+
+	var val string
+	ok, err = store.Get("test", &val)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "bar", val)
+}
+
+func TestStorage_CompareAndSwap_NoCASMemory(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	store := NewStorage(logger)
+
+	// Embedding the Memory interface hides the CompareAndSwap function of the
+	// inMemory type so the Storage must fall back to its own implementation.
+	mem := struct{ Memory }{newInMemory()}
+	store.SetMemory(mem)
+
+	ok, err := store.CompareAndSwap("test", nil, "foo")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = store.CompareAndSwap("test", "bar", "baz")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = store.CompareAndSwap("test", "foo", "bar")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	var val string
+	ok, err = store.Get("test", &val)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "bar", val)
+}
+
+func TestStorage_Update(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	store := NewStorage(logger)
+
+	var counter int
+	increment := func() error {
+		counter++
+		return nil
+	}
+
+	err := store.Update("counter", &counter, increment)
+	require.NoError(t, err, "should update a key that does not exist yet")
+	assert.Equal(t, 1, counter)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var n int
+			err := store.Update("counter", &n, func() error {
+				n++
+				return nil
+			})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	var actual int
+	ok, err := store.Get("counter", &actual)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 51, actual)
+}
+
+func TestStorage_Update_Errors(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	store := NewStorage(logger)
+	require.NoError(t, store.Set("test", "foo"))
+
+	var val string
+	err := store.Update("test", val, func() error { return nil })
+	assert.EqualError(t, err, "update target must be a non-nil pointer")
+
+	mutateErr := errors.New("something went wrong")
+	err = store.Update("test", &val, func() error {
+		val = "bar"
+		return mutateErr
+	})
+	assert.Equal(t, mutateErr, err)
+
+	ok, err := store.Get("test", &val)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "foo", val, "value should not be stored if mutate fails")
+
+	store.SetMemory(conflictMemory{newInMemory()})
+	err = store.Update("test", &val, func() error { return nil })
+	assert.EqualError(t, err, `failed to update key "test" after 10 attempts: concurrent modification`)
+	assert.True(t, errors.Is(err, ErrConcurrentModification))
+}
+
+// conflictMemory is a CASMemory that simulates a value which is always
+// modified concurrently.
+type conflictMemory struct {
+	*inMemory
+}
+
+func (conflictMemory) CompareAndSwap(string, []byte, []byte) (bool, error) {
+	return false, nil
+}
+
+func TestStorage_Ping(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	store := NewStorage(logger)
+	assert.NoError(t, store.Ping(), "default memory should always be available")
+
+	pingErr := errors.New("connection refused")
+	store.SetMemory(pingMemory{inMemory: newInMemory(), err: pingErr})
+	err := store.Ping()
+	assert.EqualError(t, err, "memory unavailable: connection refused")
+	assert.True(t, errors.Is(err, ErrMemoryUnavailable))
+	assert.True(t, errors.Is(err, pingErr))
+
+	wrappedErr := fmt.Errorf("redis: %w", ErrMemoryUnavailable)
+	store.SetMemory(pingMemory{inMemory: newInMemory(), err: wrappedErr})
+	assert.Equal(t, wrappedErr, store.Ping(), "errors that already match ErrMemoryUnavailable should not be wrapped again")
+}
+
+type pingMemory struct {
+	*inMemory
+	err error
+}
+
+func (m pingMemory) Ping() error {
+	return m.err
+}
+
+func TestStorage_ExportImport(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	src := NewStorage(logger)
+	require.NoError(t, src.Set("foo", "bar"))
+	require.NoError(t, src.Set("answer", 42))
+	require.NoError(t, src.memory.Set("binary", []byte{0x00, 0xff, 0x0a}))
+
+	var buf bytes.Buffer
+	err := src.Export(&buf)
+	require.NoError(t, err)
+
+	expected := `{"key":"answer","value":"NDI="}` + "\n" +
+		`{"key":"binary","value":"AP8K"}` + "\n" +
+		`{"key":"foo","value":"ImJhciI="}` + "\n"
+	assert.Equal(t, expected, buf.String())
+
+	dst := NewStorage(logger)
+	require.NoError(t, dst.Set("foo", "overwritten"))
+	require.NoError(t, dst.Set("other", "untouched"))
+
+	err = dst.Import(&buf)
+	require.NoError(t, err)
+
+	keys, err := dst.Keys()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"answer", "binary", "foo", "other"}, keys)
+
+	var foo string
+	_, err = dst.Get("foo", &foo)
+	require.NoError(t, err)
+	assert.Equal(t, "bar", foo)
+
+	binary, _, err := dst.memory.Get("binary")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x00, 0xff, 0x0a}, binary)
+}
+
+func TestStorage_Import_Errors(t *testing.T) {
+	store := NewStorage(zaptest.NewLogger(t))
+
+	err := store.Import(strings.NewReader(`{"key":"foo","value":"ImJhciI="}` + "\n" + `{"key":`))
+	assert.EqualError(t, err, "failed to decode entry 2: unexpected EOF")
+
+	err = store.Import(strings.NewReader(`{"value":"ImJhciI="}`))
+	assert.EqualError(t, err, "entry 1 has no key")
+
+	ok, err := store.Get("foo", nil)
+	require.NoError(t, err)
+	assert.True(t, ok, "entries before the error should be imported")
+}
+
+func TestMigrateMemory(t *testing.T) {
+	src := newInMemory()
+	require.NoError(t, src.Set("foo", []byte("new foo")))
+	require.NoError(t, src.Set("bar", []byte("new bar")))
+
+	dst := newInMemory()
+	require.NoError(t, dst.Set("foo", []byte("old foo")))
+	require.NoError(t, dst.Set("baz", []byte("old baz")))
+
+	core, logs := observer.New(zap.InfoLevel)
+	err := MigrateMemory(src, dst, MigrateLogger(zap.New(core)))
+	require.NoError(t, err)
+
+	assertMemory(t, dst, map[string]string{
+		"foo": "old foo",
+		"bar": "new bar",
+		"baz": "old baz",
+	})
+
+	entries := logs.FilterMessage("Memory migration completed").AllUntimed()
+	require.Len(t, entries, 1)
+	assert.Equal(t, map[string]interface{}{"copied": int64(1), "skipped": int64(1)}, entries[0].ContextMap())
+
+	// Running the migration again should not change anything.
+	require.NoError(t, MigrateMemory(src, dst))
+	assertMemory(t, dst, map[string]string{
+		"foo": "old foo",
+		"bar": "new bar",
+		"baz": "old baz",
+	})
+
+	require.NoError(t, MigrateMemory(src, dst, MigrateOverwrite()))
+	assertMemory(t, dst, map[string]string{
+		"foo": "new foo",
+		"bar": "new bar",
+		"baz": "old baz",
+	})
+}
+
+func assertMemory(t *testing.T, m Memory, expected map[string]string) {
+	t.Helper()
+
+	keys, err := m.Keys()
+	require.NoError(t, err)
+
+	actual := map[string]string{}
+	for _, key := range keys {
+		value, _, err := m.Get(key)
+		require.NoError(t, err)
+		actual[key] = string(value)
+	}
+
+	assert.Equal(t, expected, actual)
+}
+
+func TestInMemory_MaxEntries(t *testing.T) {
+	mem := newInMemory()
+	mem.maxEntries = 2
+
+	require.NoError(t, mem.Set("a", []byte("1")))
+	require.NoError(t, mem.Set("b", []byte("2")))
+
+	_, ok, err := mem.Get("a") // "a" is now the most recently used entry
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	require.NoError(t, mem.Set("c", []byte("3")))
+
+	_, ok, err = mem.Get("b")
+	require.NoError(t, err)
+	assert.False(t, ok, "least recently used entry should have been evicted")
+
+	keys, err := mem.Keys()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"a", "c"}, keys)
+}
+
+func TestInMemory_MaxBytes(t *testing.T) {
+	mem := newInMemory()
+	mem.maxBytes = 10
+
+	require.NoError(t, mem.Set("a", []byte("1234"))) // 5 bytes
+	require.NoError(t, mem.Set("b", []byte("1234"))) // 10 bytes
+	require.NoError(t, mem.Set("a", []byte("12")))   // 8 bytes
+	assert.Equal(t, 8, mem.size)
+
+	require.NoError(t, mem.Set("c", []byte("1234"))) // 13 bytes so "b" is evicted
+	assert.Equal(t, 8, mem.size)
+
+	keys, err := mem.Keys()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"a", "c"}, keys)
+
+	ok, err := mem.Delete("a")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 5, mem.size)
+
+	err = mem.Set("d", []byte("this is way too large"))
+	assert.EqualError(t, err, `value of key "d" exceeds memory limit of 10 bytes`)
+
+	require.NoError(t, mem.Close())
+	assert.Equal(t, 0, mem.size)
+}
+
 func TestStorage_Encoder(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 	enc := new(gobEncoder)
@@ -74,6 +394,34 @@ func TestStorage_Encoder(t *testing.T) {
 	assert.Equal(t, val, actual)
 }
 
+func TestStorage_SetWith(t *testing.T) {
+	store := NewStorage(zaptest.NewLogger(t))
+	enc := new(gobEncoder)
+
+	val := []string{"foo", "bar"}
+	require.NoError(t, store.SetWith("gob", val, enc))
+	require.NoError(t, store.SetWith("json", val, nil))
+
+	data, _, err := store.memory.Get("json")
+	require.NoError(t, err)
+	assert.Equal(t, `["foo","bar"]`, string(data), "a nil encoder should use the default encoder")
+
+	var actual []string
+	ok, err := store.GetWith("gob", &actual, enc)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, val, actual)
+
+	_, err = store.Get("gob", &actual)
+	assert.Error(t, err, "the value should not be encoded with the default encoder")
+
+	actual = nil
+	ok, err = store.GetWith("json", &actual, nil)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, val, actual)
+}
+
 func TestStorage_EncoderErrors(t *testing.T) {
 	logger := zaptest.NewLogger(t)
 	enc := new(gobEncoder)
@@ -93,6 +441,117 @@ func TestStorage_EncoderErrors(t *testing.T) {
 	ok, err := store.Get("test", &actual)
 	assert.EqualError(t, err, "decode data: this did not work")
 	assert.False(t, ok)
+
+	ok, err = store.Has("test")
+	assert.NoError(t, err, "Has should never decode the value")
+	assert.True(t, ok)
+}
+
+func TestStorage_Has(t *testing.T) {
+	store := NewStorage(zaptest.NewLogger(t))
+
+	ok, err := store.Has("test")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, store.Set("test", "foo"))
+
+	ok, err = store.Has("test")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	memErr := errors.New("connection lost")
+	store.SetMemory(failingMemory{inMemory: newInMemory(), err: memErr})
+	ok, err = store.Has("test")
+	assert.Equal(t, memErr, err)
+	assert.False(t, ok)
+}
+
+func TestStorage_Ctx(t *testing.T) {
+	store := NewStorage(zaptest.NewLogger(t))
+	mem := &contextMemory{inMemory: newInMemory()}
+	store.SetMemory(mem)
+
+	type testKey struct{}
+	ctx := context.WithValue(context.Background(), testKey{}, "test")
+
+	require.NoError(t, store.SetCtx(ctx, "foo", "bar"))
+	var value string
+	ok, err := store.GetCtx(ctx, "foo", &value)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "bar", value)
+	ok, err = store.DeleteCtx(ctx, "foo")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	require.Len(t, mem.contexts, 4, "delete should also look up the indexes of the key")
+	for _, c := range mem.contexts {
+		assert.Equal(t, "test", c.Value(testKey{}), "context should be passed to the memory")
+	}
+
+	// The methods without context use the background context.
+	require.NoError(t, store.Set("foo", "bar"))
+	assert.Equal(t, context.Background(), mem.contexts[4])
+}
+
+func TestStorage_Ctx_Canceled(t *testing.T) {
+	store := NewStorage(zaptest.NewLogger(t))
+	require.NoError(t, store.Set("foo", "bar"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// The default Memory does not implement the ContextMemory interface so
+	// the context is checked before the Memory is accessed.
+	err := store.SetCtx(ctx, "foo", "baz")
+	assert.Equal(t, context.Canceled, err)
+
+	var value string
+	ok, err := store.GetCtx(ctx, "foo", &value)
+	assert.Equal(t, context.Canceled, err)
+	assert.False(t, ok)
+
+	ok, err = store.DeleteCtx(ctx, "foo")
+	assert.Equal(t, context.Canceled, err)
+	assert.False(t, ok)
+
+	ok, err = store.Get("foo", &value)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "bar", value, "value should not have been changed")
+}
+
+// contextMemory is a Memory that implements the ContextMemory interface and
+// records all contexts it receives.
+type contextMemory struct {
+	*inMemory
+	contexts []context.Context
+}
+
+func (m *contextMemory) GetCtx(ctx context.Context, key string) ([]byte, bool, error) {
+	m.contexts = append(m.contexts, ctx)
+	return m.Get(key)
+}
+
+func (m *contextMemory) SetCtx(ctx context.Context, key string, value []byte) error {
+	m.contexts = append(m.contexts, ctx)
+	return m.Set(key, value)
+}
+
+func (m *contextMemory) DeleteCtx(ctx context.Context, key string) (bool, error) {
+	m.contexts = append(m.contexts, ctx)
+	return m.Delete(key)
+}
+
+// failingMemory is a Memory whose backend is not reachable.
+type failingMemory struct {
+	*inMemory
+	err error
+}
+
+func (m failingMemory) Get(string) ([]byte, bool, error) {
+	return nil, false, m.err
 }
 
 // gobEncoder is an example of a different encoder. This is not part of joe to
@@ -0,0 +1,181 @@
+package joe
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// disabledCommandsKeyPrefix is the key prefix in the Storage under which the
+// names of all disabled commands of a channel are stored.
+const disabledCommandsKeyPrefix = "joe.commands.disabled."
+
+// WithCommandToggles is an option to let admins disable and enable commands per
+// channel at runtime. A command is identified by its name (see
+// CommandInfo.Name()) and a disabled command simply does not match any
+// messages in that channel so they are passed to the other handlers instead.
+// The disabled commands are stored in the Storage of the bot so they survive
+// restarts if the Memory is persistent.
+//
+// This option also registers the following commands which can only be used by
+// users that have been granted the given scope (see Auth.Grant(…)):
+//
+//	disable <command> [in <channel>]
+//	enable <command> [in <channel>]
+//
+// If no channel is given, the command is toggled in the channel in which the
+// message was received. Commands that are disabled via Bot.DisableCommand(…)
+// are only respected if this option is used.
+func WithCommandToggles(adminScope string) Module {
+	return ModuleFunc(func(conf *Config) error {
+		if adminScope == "" {
+			return errors.New("command toggles scope must not be empty")
+		}
+
+		conf.commandToggles = true
+		conf.commandTogglesScope = adminScope
+		return nil
+	})
+}
+
+// DisableCommand disables the command with the given name in the given channel.
+// An error is returned if the bot has no command with that name.
+func (b *Bot) DisableCommand(channel, name string) error {
+	return b.toggleCommand(channel, name, false)
+}
+
+// EnableCommand enables a command that was disabled in the given channel via
+// Bot.DisableCommand(…) again. An error is returned if the bot has no command
+// with that name.
+func (b *Bot) EnableCommand(channel, name string) error {
+	return b.toggleCommand(channel, name, true)
+}
+
+// DisabledCommands returns the sorted names of all commands that are disabled
+// in the given channel.
+func (b *Bot) DisabledCommands(channel string) ([]string, error) {
+	var names []string
+	_, err := b.Store.Get(disabledCommandsKeyPrefix+channel, &names)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load disabled commands: %w", err)
+	}
+
+	return names, nil
+}
+
+func (b *Bot) toggleCommand(channel, name string, enable bool) error {
+	name = strings.ToLower(name)
+	if !b.hasCommand(name) {
+		return fmt.Errorf("unknown command %q", name)
+	}
+
+	var names []string
+	return b.Store.Update(disabledCommandsKeyPrefix+channel, &names, func() error {
+		disabled := map[string]bool{}
+		for _, n := range names {
+			disabled[n] = true
+		}
+
+		disabled[name] = !enable
+		names = names[:0]
+		for n, ok := range disabled {
+			if ok {
+				names = append(names, n)
+			}
+		}
+
+		sort.Strings(names)
+		return nil
+	})
+}
+
+func (b *Bot) hasCommand(name string) bool {
+	for _, cmd := range b.Commands() {
+		if name != "" && cmd.Name() == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// commandEnabled returns true if the command with the given name is not
+// disabled in the channel. Commands without a name cannot be disabled.
+func (b *Bot) commandEnabled(channel, name string) bool {
+	if name == "" {
+		return true
+	}
+
+	disabled, err := b.DisabledCommands(channel)
+	if err != nil {
+		// We rather keep the bot working than to block all commands.
+		b.Logger.Warn("Failed to check if command is enabled",
+			zap.String("command", name),
+			zap.String("channel", channel),
+			zap.Error(err),
+		)
+		return true
+	}
+
+	for _, n := range disabled {
+		if n == name {
+			return false
+		}
+	}
+
+	return true
+}
+
+// registerCommandToggles registers the commands of WithCommandToggles(…). They
+// are registered without a command name so they can never be disabled.
+func (b *Bot) registerCommandToggles(scope string) {
+	for _, action := range []string{"disable", "enable"} {
+		expr := `^` + action + `\s+(\S+)(?:\s+in\s+(\S+))?$`
+		enable := action == "enable"
+		if b.respondRegex(expr, "", nil, b.messageHandler(expr, func(msg Message) error {
+			return b.handleCommandToggle(scope, enable, msg)
+		})) {
+			b.addCommand(CommandInfo{Pattern: expr})
+		}
+	}
+}
+
+func (b *Bot) handleCommandToggle(scope string, enable bool, msg Message) error {
+	err := b.Auth.CheckPermission(scope, msg.AuthorID)
+	if errors.Is(err, ErrNotAllowed) {
+		return msg.RespondE("Sorry, you are not allowed to do that")
+	}
+	if err != nil {
+		return err
+	}
+
+	name, channel := strings.ToLower(msg.Matches[0]), msg.Matches[1]
+	if channel == "" {
+		channel = msg.Channel
+	}
+
+	if !b.hasCommand(name) {
+		return msg.RespondE("Unknown command %q", name)
+	}
+
+	action := "Disabled"
+	if enable {
+		action = "Enabled"
+	}
+
+	err = b.toggleCommand(channel, name, enable)
+	if err != nil {
+		return err
+	}
+
+	b.Logger.Info(action+" command",
+		zap.String("command", name),
+		zap.String("channel", channel),
+		zap.String("user_id", msg.AuthorID),
+	)
+
+	return msg.RespondE("%s %s in %s", action, name, channel)
+}
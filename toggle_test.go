@@ -0,0 +1,98 @@
+package joe_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-joe/joe"
+	"github.com/go-joe/joe/joetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithCommandToggles(t *testing.T) {
+	b := joetest.NewBot(t, joe.WithCommandToggles("admin"))
+	b.Respond("deploy", func(msg joe.Message) error {
+		msg.Respond("deploying")
+		return nil
+	})
+
+	_, err := b.Auth.Grant("admin", "alice")
+	require.NoError(t, err)
+
+	b.Start()
+	defer b.Stop()
+
+	send := func(channel, author, text string) string {
+		b.ReadOutput() // discard any previous output
+		b.EmitSync(joe.ReceiveMessageEvent{Text: text, Channel: channel, AuthorID: author})
+		return strings.TrimSuffix(b.ReadOutput(), "\n")
+	}
+
+	assert.Equal(t, "deploying", send("general", "bob", "deploy"))
+	assert.Equal(t, "Sorry, you are not allowed to do that", send("general", "bob", "disable deploy"))
+	assert.Equal(t, "Unknown command \"rollback\"", send("general", "alice", "disable rollback"))
+
+	assert.Equal(t, "Disabled deploy in general", send("general", "alice", "disable deploy"))
+	assert.Equal(t, "", send("general", "bob", "deploy"))
+	assert.Equal(t, "deploying", send("ops", "bob", "deploy"), "command should only be disabled in one channel")
+
+	assert.Equal(t, "Disabled deploy in ops", send("general", "alice", "Disable Deploy in ops"))
+	assert.Equal(t, "", send("ops", "bob", "deploy"))
+
+	disabled, err := b.DisabledCommands("general")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"deploy"}, disabled)
+
+	assert.Equal(t, "Enabled deploy in general", send("general", "alice", "enable deploy"))
+	assert.Equal(t, "deploying", send("general", "bob", "deploy"))
+}
+
+func TestBot_DisableCommand(t *testing.T) {
+	b := joetest.NewBot(t, joe.WithCommandToggles("admin"))
+
+	var calls int
+	b.RespondAliases([]string{"deploy", "ship"}, func(joe.Message) error {
+		calls++
+		return nil
+	})
+
+	assert.EqualError(t, b.DisableCommand("general", "rollback"), `unknown command "rollback"`)
+	require.NoError(t, b.DisableCommand("general", "deploy"))
+
+	b.Start()
+	defer b.Stop()
+
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "deploy", Channel: "general"})
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "ship", Channel: "general"})
+	assert.Equal(t, 0, calls, "aliases should be disabled together with the command")
+
+	require.NoError(t, b.EnableCommand("general", "deploy"))
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "ship", Channel: "general"})
+	assert.Equal(t, 1, calls)
+}
+
+func TestBot_DisableCommand_WithoutToggles(t *testing.T) {
+	b := joetest.NewBot(t)
+
+	var calls int
+	b.Respond("deploy", func(joe.Message) error {
+		calls++
+		return nil
+	})
+
+	require.NoError(t, b.DisableCommand("general", "deploy"))
+
+	b.Start()
+	defer b.Stop()
+
+	b.EmitSync(joe.ReceiveMessageEvent{Text: "deploy", Channel: "general"})
+	assert.Equal(t, 1, calls, "disabled commands should only be respected with WithCommandToggles")
+}
+
+func TestWithCommandToggles_EmptyScope(t *testing.T) {
+	b := joetest.NewBot(t, joe.WithCommandToggles(""))
+	err := b.Run()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "command toggles scope must not be empty")
+}
@@ -0,0 +1,30 @@
+package joe
+
+import (
+	"context"
+)
+
+// A Tracer can be used to trace the event handling of the Brain (e.g. via
+// OpenTelemetry). The contexts that are returned by the Tracer are passed to
+// the event handlers, so they can carry a span which handlers can use to start
+// child spans for any downstream calls (e.g. via Message.Context).
+type Tracer interface {
+	// StartEvent is called before an event is dispatched to its handlers.
+	// The returned function is called when all handlers have processed the
+	// event.
+	StartEvent(ctx context.Context, eventType string) (context.Context, func())
+
+	// StartHandler is called before a single handler is executed. The returned
+	// function is called with the error of the handler (if any) when the
+	// handler has returned.
+	StartHandler(ctx context.Context, eventType, handler string) (context.Context, func(error))
+}
+
+// WithTracer is an option to trace all event handling via the given Tracer. By
+// default no Tracer is used.
+func WithTracer(t Tracer) Module {
+	return ModuleFunc(func(conf *Config) error {
+		conf.tracer = t
+		return nil
+	})
+}
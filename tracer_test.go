@@ -0,0 +1,67 @@
+package joe_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/go-joe/joe"
+	"github.com/go-joe/joe/joetest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTracer(t *testing.T) {
+	tracer := new(testTracer)
+	b := joetest.NewBot(t, joe.WithTracer(tracer))
+
+	type TestEvent struct{}
+
+	handlerErr := errors.New("handler failed")
+	b.Brain.RegisterHandler(func(ctx context.Context, evt TestEvent) error {
+		assert.Equal(t, "joe_test.TestEvent", ctx.Value(spanKey("event")))
+		assert.NotNil(t, ctx.Value(spanKey("handler")))
+		return handlerErr
+	})
+
+	b.Start()
+	b.EmitSync(TestEvent{})
+	b.Stop()
+
+	tracer.mu.Lock()
+	defer tracer.mu.Unlock()
+
+	assert.Contains(t, tracer.spans, "start event joe_test.TestEvent")
+	assert.Contains(t, tracer.spans, "end event joe_test.TestEvent")
+	assert.Contains(t, tracer.spans, "end handler joe_test.TestEvent: handler failed")
+}
+
+type spanKey string
+
+type testTracer struct {
+	mu    sync.Mutex
+	spans []string
+}
+
+func (tr *testTracer) record(span string) {
+	tr.mu.Lock()
+	tr.spans = append(tr.spans, span)
+	tr.mu.Unlock()
+}
+
+func (tr *testTracer) StartEvent(ctx context.Context, eventType string) (context.Context, func()) {
+	tr.record("start event " + eventType)
+	ctx = context.WithValue(ctx, spanKey("event"), eventType)
+	return ctx, func() {
+		tr.record("end event " + eventType)
+	}
+}
+
+func (tr *testTracer) StartHandler(ctx context.Context, eventType, handler string) (context.Context, func(error)) {
+	ctx = context.WithValue(ctx, spanKey("handler"), handler)
+	return ctx, func(err error) {
+		if err != nil {
+			tr.record("end handler " + eventType + ": " + err.Error())
+		}
+	}
+}
@@ -5,4 +5,17 @@ type User struct {
 	ID       string
 	Name     string
 	RealName string
+	IsBot    bool // true if the user is a bot or integration, if known by the Adapter
 }
+
+// Presence describes if a user is currently available in the chat.
+type Presence string
+
+// The possible Presence values. Adapters should map the presence of the chat
+// to the closest of these values.
+const (
+	PresenceOnline  Presence = "online"
+	PresenceAway    Presence = "away"
+	PresenceOffline Presence = "offline"
+	PresenceDND     Presence = "dnd" // do not disturb
+)